@@ -3,6 +3,7 @@ package main
 import (
 	"city-route-game/internal/admin"
 	"city-route-game/internal/app"
+	"city-route-game/internal/disk_board_image_store"
 	"city-route-game/internal/gorm_board_crud_repository"
 	"flag"
 	"fmt"
@@ -25,12 +26,14 @@ func main() {
 	var assetHost string
 	var databaseUrl string
 	var ipWhitelist string
+	var boardImageDir string
 	flag.StringVar(&listenAddr, "listenaddr", "", "address to listen on (default \"\")")
 	flag.IntVar(&port, "port", 8080, "port to listen on (default 8080)")
 	flag.BoolVar(&migrate, "migrate", false, "Migrate gorm_board_crud_repository on startup")
 	flag.StringVar(&assetHost, "assethost", "", "Optional asset host domain")
 	flag.StringVar(&databaseUrl, "gorm_board_crud_repository-url", "host=localhost user=william password=password dbname=hansa_dev port=5432 sslmode=disable TimeZone=UTC", "Database URL")
 	flag.StringVar(&ipWhitelist, "ipwhitelist", "", "Optional IP Whitelist")
+	flag.StringVar(&boardImageDir, "board-image-dir", "./data/board_images", "Directory to store board background images in")
 	flag.Parse()
 
 	fmt.Println("Database URL:", databaseUrl)
@@ -44,7 +47,7 @@ func main() {
 	}
 
 	if migrate {
-		err = db.AutoMigrate(gorm_board_crud_repository.Models()...)
+		err = gorm_board_crud_repository.Migrate(db)
 		if err != nil {
 			panic("Error migrating gorm_board_crud_repository: " + err.Error())
 		}
@@ -59,7 +62,14 @@ func main() {
 	}
 
 	boardRepo := gorm_board_crud_repository.NewGormBoardCrudRepository(db)
-	boardEditorService := app.NewBoardEditorService(boardRepo)
+	boardEvents := app.NewBoardEventBroker()
+
+	boardImageStore, err := disk_board_image_store.NewDiskBoardImageStore(boardImageDir)
+	if err != nil {
+		panic("Error opening board image directory: " + err.Error())
+	}
+	boardEditorService := app.NewBoardEditorService(boardRepo, boardEvents, boardImageStore)
+	boardImageService := app.NewBoardImageService(boardImageStore, boardEditorService)
 
 	controllerConfig := admin.ControllerConfig{
 		FormDecoder: schema.NewDecoder(),
@@ -67,10 +77,12 @@ func main() {
 		AssetHost: "",
 	}
 
-	boardController := admin.NewBoardController(controllerConfig, boardEditorService)
+	boardController := admin.NewBoardController(controllerConfig, boardEditorService, boardEvents)
+	boardImageController := admin.NewBoardImageController(controllerConfig, boardImageService)
 	cityController := admin.NewCityController(controllerConfig, boardEditorService)
+	routeController := admin.NewRouteController(controllerConfig, boardEditorService)
 
-	router := admin.NewAdminRouter(&boardController, &cityController, splitIPs, true)
+	router := admin.NewAdminRouter(&boardController, &boardImageController, &cityController, &routeController, splitIPs, true)
 
 	listenAddrFull := fmt.Sprintf("%s:%d", listenAddr, port)
 	fmt.Println("Listening on", listenAddrFull)
@@ -1,14 +1,22 @@
 package admin
 
 import (
+	"bufio"
 	"bytes"
 	"city-route-game/httpassert"
 	"city-route-game/internal/app"
+	"city-route-game/internal/disk_board_image_store"
 	"city-route-game/internal/gorm_board_crud_repository"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/gorilla/schema"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -27,15 +35,21 @@ var (
 	testData TestData
 	repo     app.BoardCrudRepository
 	boardEditorService app.BoardEditorService
+	boardEvents *app.BoardEventBroker
+	testDB   *gorm.DB
 )
 
 func TestMain(m *testing.M) {
-	dbPath := "../data/admin-test.sqlite"
+	dbPath := "../../data/admin-test.sqlite"
+	boardImageDir := "../../data/admin-test-board-images"
 
 	err := os.Remove(dbPath)
 	if err != nil && !os.IsNotExist(err) {
 		panic("Error deleting prior test gorm_board_crud_repository: " + err.Error())
 	}
+	if err = os.RemoveAll(boardImageDir); err != nil {
+		panic("Error deleting prior test board images: " + err.Error())
+	}
 
 	dbConn, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -49,20 +63,29 @@ func TestMain(m *testing.M) {
 		panic("Error migrating gorm_board_crud_repository: " + err.Error())
 	}
 
+	testDB = dbConn
 	repo = gorm_board_crud_repository.NewGormBoardCrudRepository(dbConn)
-	boardEditorService = app.NewBoardEditorService(repo)
+	boardEvents = app.NewBoardEventBroker()
+	boardImageStore, err := disk_board_image_store.NewDiskBoardImageStore(boardImageDir)
+	if err != nil {
+		panic("Error opening board image directory: " + err.Error())
+	}
+	boardEditorService = app.NewBoardEditorService(repo, boardEvents, boardImageStore)
 
 	controllerConfig := ControllerConfig{
 		FormDecoder: schema.NewDecoder(),
-		TemplateRoot: "../templates",
+		TemplateRoot: "../../templates",
 		AssetHost: "",
 	}
 
-	boardController := NewBoardController(controllerConfig, boardEditorService)
+	boardController := NewBoardController(controllerConfig, boardEditorService, boardEvents)
+	boardImageService := app.NewBoardImageService(boardImageStore, boardEditorService)
+	boardImageController := NewBoardImageController(controllerConfig, boardImageService)
 	cityController := NewCityController(controllerConfig, boardEditorService)
+	routeController := NewRouteController(controllerConfig, boardEditorService)
 
 	testData = insertTestData(context.Background())
-	router = NewAdminRouter(&boardController, &cityController, []string{}, false)
+	router = NewAdminRouter(&boardController, &boardImageController, &cityController, &routeController, []string{}, false)
 
 	os.Exit(m.Run())
 }
@@ -78,6 +101,51 @@ func TestListBoards(t *testing.T) {
 	httpassert.HtmlContentType(t, w)
 }
 
+func TestSearchBoards(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+
+	req := httptest.NewRequest("GET", "/boards/?q="+url.QueryEscape(board.Name)+"&sort=updated&order=desc&perPage=5", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+
+	var page app.BoardPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Boards) != 1 || page.Boards[0].ID != board.ID {
+		t.Errorf("expected only board %d to match, got %+v", board.ID, page)
+	}
+	if page.PerPage != 5 {
+		t.Errorf("expected 5 boards per page, got %d", page.PerPage)
+	}
+
+	req = httptest.NewRequest("GET", "/boards/?q="+url.QueryEscape(board.Name)+"&page=2", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+	httpassert.HtmlContentType(t, w)
+
+	req = httptest.NewRequest("GET", "/boards/?sort=width", nil)
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an unknown sort, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/boards/?page=zero", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an invalid page, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 func TestNewBoard(t *testing.T) {
 	req := httptest.NewRequest("GET", "/boards/new", nil)
 	req.Header.Set("Accept", "text/html")
@@ -238,196 +306,2154 @@ func Test_update_board_dimensions_via_json(t *testing.T) {
 	}
 }
 
-func TestListCitiesByBoardId_boardNotFound(t *testing.T) {
-	req := httptest.NewRequest("GET", "/boards/9999/cities/", nil)
+func Test_resize_board_moves_cities(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	boardID := fmt.Sprint(board.ID)
+	city, err := boardEditorService.CreateCity(ctx, boardID, &app.CityForm{
+		Name:     "Lübeck",
+		Position: app.Position{X: 400, Y: 300},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resize := func(fields map[string]interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/boards/%d", board.ID), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	position := func() app.Position {
+		found, err := repo.GetCityByID(ctx, city.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return found.Position
+	}
+
+	w := resize(map[string]interface{}{"name": board.Name, "width": 400, "height": 300, "resizeMode": "scale"})
+	if !httpassert.Success(t, w) {
+		t.Log("Body: ", w.Body)
+	}
+	if actual := position(); actual != (app.Position{X: 200, Y: 150}) {
+		t.Errorf("city should have been scaled to (200, 150), was %+v", actual)
+	}
+
+	w = resize(map[string]interface{}{"name": board.Name, "width": 600, "height": 500, "resizeMode": "anchor", "resizeAnchor": "center"})
+	httpassert.Success(t, w)
+	if actual := position(); actual != (app.Position{X: 300, Y: 250}) {
+		t.Errorf("city should have been moved to (300, 250), was %+v", actual)
+	}
+
+	// Undoing the resize puts the city back too
+	if _, err = boardEditorService.Undo(ctx, boardID); err != nil {
+		t.Fatal(err)
+	}
+	if actual := position(); actual != (app.Position{X: 200, Y: 150}) {
+		t.Errorf("undo should have moved the city back to (200, 150), was %+v", actual)
+	}
+
+	w = resize(map[string]interface{}{"name": board.Name, "width": 800, "height": 600})
+	httpassert.Success(t, w)
+	if actual := position(); actual != (app.Position{X: 200, Y: 150}) {
+		t.Errorf("cities should be kept in place by default, was %+v", actual)
+	}
+
+	w = resize(map[string]interface{}{"name": board.Name, "width": 100, "height": 100, "resizeMode": "stretch"})
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected 400 Bad Request for an unknown resize mode but got", w.Code)
+	}
+}
+
+func Test_update_board_with_stale_version(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+
+	patch := func(name string, ifMatch string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(map[string]interface{}{
+			"name":   name,
+			"width":  board.Width,
+			"height": board.Height,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/boards/%d", board.ID), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		req.Header.Set("If-Match", ifMatch)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	firstName := fmt.Sprintf("%s (first)", board.Name)
+	w := patch(firstName, fmt.Sprintf(`"%d"`, board.Version))
+	if !httpassert.Success(t, w) {
+		t.Log("Body: ", w.Body)
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf(`"%d"`, board.Version+1) {
+		t.Errorf("ETag should be the new version (was %s)", w.Header().Get("ETag"))
+	}
+
+	// A second client still holding the original version
+	w = patch(fmt.Sprintf("%s (second)", board.Name), fmt.Sprintf(`"%d"`, board.Version))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Response code is not 409 (is %d)", w.Code)
+	}
+
+	var body struct {
+		Board  app.Board           `json:"board"`
+		Errors map[string][]string `json:"errors"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Board.Name != firstName {
+		t.Errorf("conflict response should contain the current board (name was %q)", body.Board.Name)
+	}
+
+	w = patch(board.Name, "not a version")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Response code is not 400 for a malformed If-Match (is %d)", w.Code)
+	}
+}
+
+func TestBoardValidation(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	createTestCitySpace(ctx, route.StartCityID, 1)
+
+	url := fmt.Sprintf("/boards/%d/validation", board.ID)
+	req := httptest.NewRequest("GET", url, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	httpassert.NotFound(t, w)
+	httpassert.Success(t, w)
+	httpassert.JsonObject(t, w)
+
+	var report app.BoardValidationReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		panic(err)
+	}
+
+	if report.Valid {
+		t.Error("board should not be valid")
+	}
+
+	codes := make(map[app.FindingCode]bool)
+	for _, finding := range report.Findings {
+		codes[finding.Code] = true
+	}
+	if !codes[app.FindingCityWithoutOffices] {
+		t.Error("expected a finding for the city without offices")
+	}
+	if !codes[app.FindingRouteWithoutSpaces] {
+		t.Error("expected a finding for the route without spaces")
+	}
+	if !codes[app.FindingDuplicateCityName] {
+		t.Error("expected a finding for the duplicate city names")
+	}
 }
 
-func TestListCitiesByBoardId(t *testing.T) {
-	board := testData.BoardWithCities
+func TestBoardStats(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	createTestCitySpace(ctx, route.StartCityID, 1)
 
-	url := fmt.Sprintf("/boards/%d/cities/", board.ID)
+	url := fmt.Sprintf("/boards/%d/stats", board.ID)
 	req := httptest.NewRequest("GET", url, nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	httpassert.Success(t, w)
-	httpassert.JsonArray(t, w)
+	httpassert.JsonObject(t, w)
 
-	responseJson := make([]app.City, 0, len(testData.BoardWithCitiesCities))
-	if err := json.NewDecoder(w.Body).Decode(&responseJson); err != nil {
+	var stats app.BoardStats
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
 		panic(err)
 	}
 
-	if len(responseJson) != len(testData.BoardWithCitiesCities) {
-		t.Error("number of cities in json doesn't match number of cities in board")
+	if stats.Cities != 2 || stats.Routes != 1 || stats.Offices != 1 {
+		t.Errorf("expected 2 cities, 1 route and 1 office, got %+v", stats)
 	}
+	if stats.Diameter != 1 {
+		t.Errorf("expected a diameter of 1, got %d", stats.Diameter)
+	}
+
+	req = httptest.NewRequest("GET", "/boards/0/stats", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.NotFound(t, w)
 }
 
-func TestCreateCity(t *testing.T) {
+func TestBoardDiff(t *testing.T) {
 	ctx := context.Background()
 	board := createTestBoard(ctx)
-	url := fmt.Sprintf("/boards/%d/cities/", board.ID)
-	city := app.CityForm{
-		Name: "Test City",
-		Position: app.Position{
-			X: 10,
-			Y: 20,
-		},
+	createTestRoute(ctx, board.ID)
+	if _, err := boardEditorService.PublishBoard(ctx, fmt.Sprint(board.ID)); err != nil {
+		t.Fatal(err)
 	}
-
-	body, err := json.Marshal(&city)
-	if err != nil {
-		panic(err)
+	added := app.City{BoardID: board.ID, Name: "Added City"}
+	if err := repo.CreateCity(ctx, &added); err != nil {
+		t.Fatal(err)
 	}
 
-	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	url := fmt.Sprintf("/boards/%d/diff?version=1", board.ID)
+	req := httptest.NewRequest("GET", url, nil)
 	req.Header.Set("Accept", "application/json")
-
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+	httpassert.JsonObject(t, w)
 
-	if !httpassert.Success(t, w) {
-		t.Log("Body:", w.Body)
+	var diff app.BoardDiff
+	if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+		t.Fatal(err)
 	}
-	httpassert.JsonContentType(t, w)
+	if len(diff.Cities) != 1 || diff.Cities[0].Name != "Added City" || diff.Cities[0].Change != app.DiffAdded {
+		t.Errorf("expected only the added city to differ, got %+v", diff.Cities)
+	}
+	if len(diff.Routes) != 0 {
+		t.Errorf("expected no routes to differ, got %+v", diff.Routes)
+	}
+
+	other := createTestBoard(ctx)
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d/diff?against=%d", board.ID, other.ID), nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+	httpassert.HtmlContentType(t, w)
+	if !strings.Contains(w.Body.String(), "Added City") {
+		t.Error("expected the HTML view to list the added city")
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d/diff", board.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d without anything to compare against, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d/diff?version=99", board.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.NotFound(t, w)
 }
 
-func TestUpdateCity(t *testing.T) {
+func TestExportAndImportBoard(t *testing.T) {
 	ctx := context.Background()
 	board := createTestBoard(ctx)
-	city := createTestCity(ctx, board.ID)
-	url := fmt.Sprintf("/boards/%d/cities/%d", board.ID, city.ID)
+	route := createTestRoute(ctx, board.ID)
+	createTestCitySpace(ctx, route.StartCityID, 1)
 
-	newName := "New City Name"
-	newX := 123
-	newY := 432
-	form := app.CityForm{
-		Name: newName,
-		Position: app.Position{
-			X: newX,
-			Y: newY,
-		},
-	}
+	exportURL := fmt.Sprintf("/boards/%d/export", board.ID)
+	req := httptest.NewRequest("GET", exportURL, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
-	body, err := json.Marshal(&form)
-	if err != nil {
-		panic(err)
+	httpassert.Success(t, w)
+	httpassert.JsonObject(t, w)
+	if !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") {
+		t.Error("export should be sent as an attachment")
 	}
+	exported := w.Body.Bytes()
 
-	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	name := fmt.Sprintf("Imported Board %d", board.ID)
+	req = httptest.NewRequest("POST", "/boards/import?name="+url.QueryEscape(name), bytes.NewReader(exported))
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 	req.Header.Set("Accept", "application/json")
-
-	w := httptest.NewRecorder()
+	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	if !httpassert.Success(t, w) {
 		t.Log("Body:", w.Body)
 	}
-	httpassert.JsonContentType(t, w)
 
-	var updatedCity app.City
-	if err = json.NewDecoder(w.Body).Decode(&updatedCity); err != nil {
+	var imported app.Board
+	if err := json.NewDecoder(w.Body).Decode(&imported); err != nil {
 		panic(err)
 	}
-
-	if updatedCity.Name != newName {
-		t.Error("City Name was not updated")
+	if imported.ID == board.ID {
+		t.Error("import should create a new board")
 	}
-	if updatedCity.Position.X != newX {
-		t.Error("City Position X was not updated")
+	if imported.Name != name {
+		t.Errorf("expected imported board to be named %q, got %q", name, imported.Name)
 	}
-	if updatedCity.Position.Y != newY {
-		t.Error("City Position Y was not updated")
+	if len(imported.Cities) != 2 || len(imported.Routes) != 1 {
+		t.Errorf("expected 2 cities and 1 route, got %d cities and %d routes", len(imported.Cities), len(imported.Routes))
+	}
+
+	// Importing again without a new name collides with the original board
+	req = httptest.NewRequest("POST", "/boards/import", bytes.NewReader(exported))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when importing under a taken name, got %d: %s", w.Code, w.Body)
 	}
 }
 
-func TestDeleteBoard(t *testing.T) {
+func TestDuplicateBoard(t *testing.T) {
 	ctx := context.Background()
 	board := createTestBoard(ctx)
-	city := createTestCity(ctx, board.ID)
-	space := app.CitySpace{
-		CityID:    city.ID,
-		Order:     1,
-		SpaceType: app.TraderID,
-	}
-	var err error
-	if err = repo.CreateCitySpace(ctx, &space); err != nil {
-		t.Fatalf("Error saving test space: %+v", err)
-	}
-	if city, err = repo.GetCityByID(ctx, city.ID); err != nil {
-		t.Fatalf("Error reloading city: %+v", err)
-	}
-	if len(city.CitySpaces) != 1 {
-		t.Error("CitySpaces HasMany relationship is not loading")
+	route := createTestRoute(ctx, board.ID)
+	createTestCitySpace(ctx, route.StartCityID, 1)
+
+	name := fmt.Sprintf("Copy of Board %d", board.ID)
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		panic(err)
 	}
 
-	req := httptest.NewRequest("DELETE", fmt.Sprintf("/boards/%d", board.ID), nil)
+	url := fmt.Sprintf("/boards/%d/duplicate", board.ID)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
-
+	req.Header.Set("Accept", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	if !httpassert.Success(t, w) {
 		t.Log("Body:", w.Body)
 	}
-	httpassert.JavascriptContentType(t, w)
-}
+	httpassert.JsonObject(t, w)
 
-func TestDeleteCity(t *testing.T) {
-	ctx := context.Background()
-	board := createTestBoard(ctx)
-	city := createTestCity(ctx, board.ID)
-	space := app.CitySpace{
-		CityID:    city.ID,
-		Order:     1,
-		SpaceType: app.TraderID,
+	var copied app.Board
+	if err = json.NewDecoder(w.Body).Decode(&copied); err != nil {
+		panic(err)
 	}
-	if err := repo.CreateCitySpace(ctx, &space); err != nil {
-		t.Fatalf("Error saving test space: %+v", err)
+	if copied.ID == board.ID {
+		t.Error("duplicate should be a new board")
 	}
-	var err error
-	if city, err = repo.GetCityByID(ctx, city.ID); err != nil {
-		t.Fatalf("Error reloading city: %+v", err)
+	if copied.Name != name {
+		t.Errorf("expected copy to be named %q, got %q", name, copied.Name)
 	}
-	if len(city.CitySpaces) != 1 {
-		t.Error("CitySpaces HasMany relationship is not loading")
+	if len(copied.Cities) != 2 || len(copied.Routes) != 1 {
+		t.Fatalf("expected 2 cities and 1 route, got %d cities and %d routes", len(copied.Cities), len(copied.Routes))
+	}
+	if copied.Routes[0].StartCityID == route.StartCityID {
+		t.Error("copied route should refer to the copied cities")
 	}
 
-	url := fmt.Sprintf("/boards/%d/cities/%d", board.ID, city.ID)
-	req := httptest.NewRequest("DELETE", url, nil)
+	// Same name again is taken
+	req = httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Response code is not 400 (is %d)", w.Code)
+		t.Log("Body:", w.Body)
+	}
+}
+
+func TestPublishBoard(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	createTestRoute(ctx, board.ID)
 
+	url := fmt.Sprintf("/boards/%d/publish", board.ID)
+	req := httptest.NewRequest("POST", url, nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusNoContent {
-		t.Errorf("Response code is not 204 (is %d)", w.Code)
+	if !httpassert.Success(t, w) {
 		t.Log("Body:", w.Body)
 	}
+	httpassert.JsonObject(t, w)
 
-	var cities []app.City
-	if cities, err = repo.ListCitiesByBoardID(ctx, city.ID); err != nil {
+	var version app.BoardVersion
+	if err := json.NewDecoder(w.Body).Decode(&version); err != nil {
 		panic(err)
 	}
+	if version.Number != 1 {
+		t.Errorf("first version should be number 1 (was %d)", version.Number)
+	}
+	if len(version.Snapshot.Cities) != 2 {
+		t.Errorf("snapshot should have 2 cities (had %d)", len(version.Snapshot.Cities))
+	}
+
+	// Editing the draft does not change the published version
+	createTestCity(ctx, board.ID)
+
+	url = fmt.Sprintf("/boards/%d/versions/1", board.ID)
+	req = httptest.NewRequest("GET", url, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.Success(t, w)
+	httpassert.JsonObject(t, w)
+
+	var published app.BoardVersion
+	if err := json.NewDecoder(w.Body).Decode(&published); err != nil {
+		panic(err)
+	}
+	if len(published.Snapshot.Cities) != 2 {
+		t.Errorf("published version should still have 2 cities (had %d)", len(published.Snapshot.Cities))
+	}
+
+	url = fmt.Sprintf("/boards/%d/versions", board.ID)
+	req = httptest.NewRequest("GET", url, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.Success(t, w)
+	httpassert.JsonArray(t, w)
+
+	url = fmt.Sprintf("/boards/%d/versions/2", board.ID)
+	req = httptest.NewRequest("GET", url, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.NotFound(t, w)
+}
+
+func TestUndoRedo(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	boardID := fmt.Sprint(board.ID)
+
+	city, err := boardEditorService.CreateCity(ctx, boardID, &app.CityForm{Name: "Lübeck"})
+	if err != nil {
+		panic(err)
+	}
+	cityID := fmt.Sprint(city.ID)
+	_, err = boardEditorService.UpdateCity(ctx, cityID, &app.CityForm{
+		Name:     "Lübeck",
+		Position: app.Position{X: 40, Y: 50},
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err = boardEditorService.DeleteCity(ctx, cityID); err != nil {
+		panic(err)
+	}
+
+	post := func(action string) (*httptest.ResponseRecorder, app.Board) {
+		url := fmt.Sprintf("/boards/%d/%s", board.ID, action)
+		req := httptest.NewRequest("POST", url, nil)
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var result app.Board
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				panic(err)
+			}
+		}
+		return w, result
+	}
+
+	// Undo the delete: the city comes back where it was moved to
+	w, result := post("undo")
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	if len(result.Cities) != 1 || result.Cities[0].ID != city.ID || result.Cities[0].Position.X != 40 {
+		t.Errorf("undoing the delete should restore the moved city, got: %+v", result.Cities)
+	}
+
+	// Undo the move
+	_, result = post("undo")
+	if len(result.Cities) != 1 || result.Cities[0].Position.X != 0 {
+		t.Errorf("undoing the move should put the city back, got: %+v", result.Cities)
+	}
+
+	// Undo the create
+	_, result = post("undo")
+	if len(result.Cities) != 0 {
+		t.Errorf("undoing the create should remove the city, got: %+v", result.Cities)
+	}
+
+	w, _ = post("undo")
+	if w.Code != http.StatusConflict {
+		t.Errorf("Response code is not 409 when there is nothing to undo (is %d)", w.Code)
+	}
+
+	// Redo the create
+	w, result = post("redo")
+	httpassert.Success(t, w)
+	if len(result.Cities) != 1 || result.Cities[0].ID != city.ID {
+		t.Errorf("redoing the create should bring the city back, got: %+v", result.Cities)
+	}
+}
+
+func TestUndoRedoRoutesAndSpaces(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	boardID := fmt.Sprint(board.ID)
+
+	lubeck, err := boardEditorService.CreateCity(ctx, boardID, &app.CityForm{Name: "Lübeck"})
+	if err != nil {
+		panic(err)
+	}
+	hamburg, err := boardEditorService.CreateCity(ctx, boardID, &app.CityForm{Name: "Hamburg", Position: app.Position{X: 200}})
+	if err != nil {
+		panic(err)
+	}
+	lubeckID := fmt.Sprint(lubeck.ID)
+
+	if _, err = boardEditorService.AddCitySpace(ctx, lubeckID, &app.AddCitySpaceForm{SpaceType: app.TraderID, RequiredPrivilege: 1}); err != nil {
+		panic(err)
+	}
+	space, err := boardEditorService.AddCitySpace(ctx, lubeckID, &app.AddCitySpaceForm{SpaceType: app.TraderID, RequiredPrivilege: 1})
+	if err != nil {
+		panic(err)
+	}
+	_, err = boardEditorService.UpdateCitySpace(ctx, fmt.Sprint(space.ID), &app.UpdateCitySpaceForm{SpaceType: app.MerchantID, RequiredPrivilege: 2})
+	if err != nil {
+		panic(err)
+	}
+
+	route, err := boardEditorService.CreateRoute(ctx, boardID, &app.RouteForm{StartCityID: lubeck.ID, EndCityID: hamburg.ID})
+	if err != nil {
+		panic(err)
+	}
+	routeID := fmt.Sprint(route.ID)
+	route, err = boardEditorService.UpdateRouteSpaces(ctx, routeID, &app.RouteSpacesForm{Spaces: []app.RouteSpaceForm{{}, {}}})
+	if err != nil {
+		panic(err)
+	}
+	if err = boardEditorService.DeleteRoute(ctx, routeID); err != nil {
+		panic(err)
+	}
+
+	post := func(action string) app.Board {
+		url := fmt.Sprintf("/boards/%d/%s", board.ID, action)
+		req := httptest.NewRequest("POST", url, nil)
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if !httpassert.Success(t, w) {
+			t.Fatalf("%s failed: %s", action, w.Body)
+		}
+
+		var result app.Board
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			panic(err)
+		}
+		return result
+	}
+
+	checkRoute := func(result app.Board, when string) {
+		if len(result.Routes) != 1 || result.Routes[0].ID != route.ID {
+			t.Fatalf("%s should bring the route back, got: %+v", when, result.Routes)
+		}
+		spaces := result.Routes[0].RouteSpaces
+		if len(spaces) != 2 || spaces[0].ID != route.RouteSpaces[0].ID || spaces[1].ID != route.RouteSpaces[1].ID {
+			t.Errorf("%s should bring the route's spaces back, got: %+v", when, spaces)
+		}
+	}
+
+	// Undo the delete of the route
+	checkRoute(post("undo"), "undoing the delete")
+
+	// Undo every other change, which removes both cities without anything left behind
+	var result app.Board
+	for i := 0; i < 7; i++ {
+		result = post("undo")
+	}
+	if len(result.Cities) != 0 || len(result.Routes) != 0 {
+		t.Errorf("undoing every change should leave an empty board, got: %+v", result)
+	}
+	var count int64
+	testDB.Table("city_spaces").Where("city_id = ?", lubeck.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("undoing the create should leave no city spaces, found %d", count)
+	}
+
+	// Redo everything up to the delete of the route
+	for i := 0; i < 7; i++ {
+		result = post("redo")
+	}
+	checkRoute(result, "redoing the route changes")
+	for _, city := range result.Cities {
+		if city.ID != lubeck.ID {
+			continue
+		}
+		if len(city.CitySpaces) != 2 || city.CitySpaces[1].ID != space.ID || city.CitySpaces[1].SpaceType != app.MerchantID {
+			t.Errorf("redoing the space changes should bring the updated spaces back, got: %+v", city.CitySpaces)
+		}
+	}
+
+	if result = post("redo"); len(result.Routes) != 0 {
+		t.Errorf("redoing the delete should remove the route again, got: %+v", result.Routes)
+	}
+}
+
+func TestUndoDeleteCityRestoresPrestigeTable(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	boardID := fmt.Sprint(board.ID)
+	city := createTestCity(ctx, board.ID)
+
+	_, err := boardEditorService.UpdatePrestigeTable(ctx, boardID, &app.PrestigeTableForm{
+		CityID: city.ID,
+		Slots:  []app.PrestigeSlotForm{{RequiredPrivilege: 1, Points: 7}, {RequiredPrivilege: 2, Points: 4}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err = boardEditorService.DeleteCity(ctx, fmt.Sprint(city.ID)); err != nil {
+		panic(err)
+	}
+	if _, err = repo.GetPrestigeTableByBoardID(ctx, board.ID); !errors.Is(app.RecordNotFound{}, err) {
+		t.Fatalf("deleting the city should delete the table it hosts, was: %+v", err)
+	}
+
+	if _, err = boardEditorService.Undo(ctx, boardID); err != nil {
+		t.Fatalf("Undo returned error: %+v", err)
+	}
+
+	table, err := repo.GetPrestigeTableByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatalf("undoing the delete should bring the table back, was: %+v", err)
+	}
+	if table.CityID != city.ID || len(table.Slots) != 2 || table.Slots[0].Points != 7 {
+		t.Errorf("undoing the delete should bring the table back as it was, got: %+v", table)
+	}
+}
+
+func TestUndoPrestigeTable(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	boardID := fmt.Sprint(board.ID)
+	first := createTestCity(ctx, board.ID)
+	second := createTestCity(ctx, board.ID)
+
+	update := func(cityID app.ID, points int) {
+		_, err := boardEditorService.UpdatePrestigeTable(ctx, boardID, &app.PrestigeTableForm{
+			CityID: cityID,
+			Slots:  []app.PrestigeSlotForm{{RequiredPrivilege: 1, Points: points}},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	version := func() int {
+		found, err := repo.GetBoardByID(ctx, board.ID)
+		if err != nil {
+			panic(err)
+		}
+		return found.Version
+	}
+
+	update(first.ID, 7)
+	update(second.ID, 5)
+	if err := boardEditorService.DeletePrestigeTable(ctx, boardID); err != nil {
+		panic(err)
+	}
+	if version() != board.Version+3 {
+		t.Errorf("every change to the table should make a new version of the board, expected %d, was %d", board.Version+3, version())
+	}
+
+	// Undo the delete
+	if _, err := boardEditorService.Undo(ctx, boardID); err != nil {
+		t.Fatalf("Undo returned error: %+v", err)
+	}
+	table, err := repo.GetPrestigeTableByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatalf("undoing the delete should bring the table back, was: %+v", err)
+	}
+	if table.CityID != second.ID || len(table.Slots) != 1 || table.Slots[0].Points != 5 {
+		t.Errorf("undoing the delete should bring the table back as it was, got: %+v", table)
+	}
+	if version() != board.Version+4 {
+		t.Errorf("undoing the delete should make a new version of the board, expected %d, was %d", board.Version+4, version())
+	}
+
+	// Undo the second update
+	if _, err = boardEditorService.Undo(ctx, boardID); err != nil {
+		t.Fatalf("Undo returned error: %+v", err)
+	}
+	table, err = repo.GetPrestigeTableByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatalf("GetPrestigeTableByBoardID returned error: %+v", err)
+	}
+	if table.CityID != first.ID || table.Slots[0].Points != 7 {
+		t.Errorf("undoing the update should put the previous table back, got: %+v", table)
+	}
+
+	// Undo the first update, which leaves the board without a table
+	if _, err = boardEditorService.Undo(ctx, boardID); err != nil {
+		t.Fatalf("Undo returned error: %+v", err)
+	}
+	if _, err = repo.GetPrestigeTableByBoardID(ctx, board.ID); !errors.Is(app.RecordNotFound{}, err) {
+		t.Errorf("undoing the first update should remove the table, was: %+v", err)
+	}
+
+	// Redo all three
+	for i := 0; i < 3; i++ {
+		if _, err = boardEditorService.Redo(ctx, boardID); err != nil {
+			t.Fatalf("Redo returned error: %+v", err)
+		}
+	}
+	if _, err = repo.GetPrestigeTableByBoardID(ctx, board.ID); !errors.Is(app.RecordNotFound{}, err) {
+		t.Errorf("redoing the delete should remove the table again, was: %+v", err)
+	}
+}
+
+func TestUndoDeleteCityRestoresConnectionObjective(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	boardID := fmt.Sprint(board.ID)
+	route := createTestRoute(ctx, board.ID)
+
+	_, err := boardEditorService.UpdateConnectionObjective(ctx, boardID, &app.ConnectionObjectiveForm{
+		StartCityID: route.StartCityID,
+		EndCityID:   route.EndCityID,
+		Awards:      []int{7, 4, 2},
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err = boardEditorService.DeleteCity(ctx, fmt.Sprint(route.EndCityID)); err != nil {
+		panic(err)
+	}
+
+	restored, err := boardEditorService.Undo(ctx, boardID)
+	if err != nil {
+		t.Fatalf("Undo returned error: %+v", err)
+	}
+	objective := restored.ConnectionObjective
+	if objective == nil || objective.EndCityID != route.EndCityID || len(objective.Awards) != 3 {
+		t.Fatalf("undoing the delete should bring the connection objective back, got: %+v", objective)
+	}
+
+	redone, err := boardEditorService.Redo(ctx, boardID)
+	if err != nil {
+		t.Fatalf("Redo returned error: %+v", err)
+	}
+	if redone.ConnectionObjective != nil {
+		t.Errorf("redoing the delete should clear the connection objective again, got: %+v", redone.ConnectionObjective)
+	}
+}
+
+func TestBoardEvents(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(streamCtx, "GET", fmt.Sprintf("%s/boards/%d/events", server.URL, board.ID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Status code is not 200 (was %d)", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Errorf("Content-Type is not text/event-stream (was %s)", resp.Header.Get("Content-Type"))
+	}
+
+	_, err = boardEditorService.UpdateCity(ctx, fmt.Sprint(city.ID), &app.CityForm{
+		Name:     "Moved City",
+		Position: app.Position{X: 7, Y: 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var eventName, data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading event stream: %+v", err)
+		}
+		if strings.HasPrefix(line, "event: ") {
+			eventName = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		} else if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	if eventName != string(app.BoardEventCityUpdated) {
+		t.Errorf("expected a %s event, got %q", app.BoardEventCityUpdated, eventName)
+	}
+	var movedCity app.City
+	if err = json.Unmarshal([]byte(data), &movedCity); err != nil {
+		t.Fatal(err)
+	}
+	if movedCity.ID != city.ID || movedCity.Position.X != 7 {
+		t.Errorf("event data should be the moved city, got: %+v", movedCity)
+	}
+}
+
+func TestGetBoardSVG(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	createTestCitySpace(ctx, route.StartCityID, 1)
+	_, err := repo.UpdateCity(ctx, route.EndCityID, func(city *app.City) (*app.City, error) {
+		city.Name = "A & <B>"
+		return city, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/boards/%d.svg", board.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.Success(t, w)
+	if contentType := w.Header().Get("Content-Type"); contentType != "image/svg+xml" {
+		t.Error("expected an SVG image but got", contentType)
+	}
+
+	decoder := xml.NewDecoder(w.Body)
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("response should be well formed XML:", err)
+		}
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d.svg", board.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "A &amp; &lt;B&gt;") {
+		t.Error("city names should be escaped")
+	}
+}
+
+func TestGetBoardSVG_boardNotFound(t *testing.T) {
+	req := httptest.NewRequest("GET", "/boards/0.svg", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.NotFound(t, w)
+}
+
+func TestBoardBackgroundImage(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	backgroundURL := fmt.Sprintf("/boards/%d/background", board.ID)
+
+	req := httptest.NewRequest("GET", backgroundURL, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.NotFound(t, w)
+
+	var imageData bytes.Buffer
+	if err := png.Encode(&imageData, image.NewRGBA(image.Rect(0, 0, 320, 240))); err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	multipartWriter := multipart.NewWriter(&body)
+	part, err := multipartWriter.CreateFormFile("image", "map.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(imageData.Bytes())
+	multipartWriter.WriteField("resizeBoard", "true")
+	multipartWriter.Close()
+
+	req = httptest.NewRequest("POST", backgroundURL, &body)
+	req.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonContentType(t, w)
+
+	resized, err := repo.GetBoardByID(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resized.Width != 320 || resized.Height != 240 {
+		t.Errorf("board should have been resized to 320x240, was %dx%d", resized.Width, resized.Height)
+	}
+
+	req = httptest.NewRequest("GET", backgroundURL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.Success(t, w)
+	if contentType := w.Header().Get("Content-Type"); contentType != "image/png" {
+		t.Error("expected a PNG image but got", contentType)
+	}
+	if !bytes.Equal(w.Body.Bytes(), imageData.Bytes()) {
+		t.Error("background should be the uploaded image")
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Error("background should be sent with ETag and Last-Modified headers")
+	}
+
+	req = httptest.NewRequest("GET", backgroundURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Error("expected 304 Not Modified for an unchanged background but got", w.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", backgroundURL, nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Error("expected 204 No Content but got", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", backgroundURL, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.NotFound(t, w)
+}
+
+func TestBoardBackgroundImage_notAnImage(t *testing.T) {
+	board := createTestBoard(context.Background())
+
+	var body bytes.Buffer
+	multipartWriter := multipart.NewWriter(&body)
+	part, err := multipartWriter.CreateFormFile("image", "map.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("not an image"))
+	multipartWriter.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/boards/%d/background", board.ID), &body)
+	req.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected 400 Bad Request but got", w.Code)
+	}
+}
+
+func TestLayoutBoard(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	createTestRoute(ctx, board.ID)
+	createTestRoute(ctx, board.ID)
+	layoutURL := fmt.Sprintf("/boards/%d/layout", board.ID)
+
+	layout := func(body string) app.BoardLayout {
+		req := httptest.NewRequest("POST", layoutURL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if !httpassert.Success(t, w) {
+			t.Fatal("Body:", w.Body)
+		}
+
+		var result app.BoardLayout
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	preview := layout(`{"dryRun": true}`)
+	if !preview.DryRun || len(preview.Cities) != 4 {
+		t.Fatalf("expected a dry run with 4 cities, got %+v", preview)
+	}
+	cities, err := repo.ListCitiesByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, city := range cities {
+		if city.Position != (app.Position{}) {
+			t.Error("a dry run should not move cities, but moved", city.Name, "to", city.Position)
+		}
+	}
+
+	saved := layout("")
+	if saved.DryRun {
+		t.Error("layout should have been saved")
+	}
+	for i, city := range saved.Cities {
+		if city.Position != preview.Cities[i].Position {
+			t.Error("saved layout should match the preview, but", city.ID, "was at", city.Position)
+		}
+		found, err := repo.GetCityByID(ctx, city.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Position != city.Position || found.Version != 2 {
+			t.Errorf("city %d should have been saved at %+v, was %+v", city.ID, city.Position, found)
+		}
+	}
+
+	// The whole layout is undone at once
+	if _, err = boardEditorService.Undo(ctx, fmt.Sprint(board.ID)); err != nil {
+		t.Fatal(err)
+	}
+	cities, err = repo.ListCitiesByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, city := range cities {
+		if city.Position != (app.Position{}) {
+			t.Error("undo should have moved", city.ID, "back, but it is at", city.Position)
+		}
+	}
+}
+
+func TestCityBatch(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	kept := createTestCity(ctx, board.ID)
+	batchURL := fmt.Sprintf("/boards/%d/cities/batch", board.ID)
+
+	batch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", batchURL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := batch(fmt.Sprintf(`{"operations": [
+		{"op": "create", "id": -1, "city": {"name": "Lübeck"}},
+		{"op": "update", "id": -1, "city": {"name": "Lübeck", "position": {"x": 10, "y": 20}}},
+		{"op": "update", "id": %d, "version": 1, "city": {"name": "Hamburg", "position": {"x": 30, "y": 40}}},
+		{"op": "delete", "id": %d},
+		{"op": "delete", "id": %d}
+	]}`, kept.ID, route.StartCityID, route.EndCityID))
+
+	if !httpassert.Success(t, w) {
+		t.Fatal("Body:", w.Body)
+	}
+
+	var result app.CityBatchResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	createdID := result.IDs[-1]
+	if createdID == 0 || len(result.IDs) != 1 {
+		t.Fatalf("expected the temporary ID to be mapped to the created city, got %+v", result.IDs)
+	}
+	if len(result.Cities) != 2 || result.Cities[0].ID != createdID || result.Cities[1].ID != kept.ID {
+		t.Fatalf("expected the created and updated cities, got %+v", result.Cities)
+	}
+	if result.Cities[0].Position != (app.Position{X: 10, Y: 20}) || result.Cities[1].Name != "Hamburg" {
+		t.Errorf("cities should be returned as they are after every operation, got %+v", result.Cities)
+	}
+	if len(result.Deleted) != 2 {
+		t.Errorf("expected 2 deleted cities, got %+v", result.Deleted)
+	}
+
+	cities, err := repo.ListCitiesByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cities) != 2 {
+		t.Errorf("expected 2 cities left on the board, got %d", len(cities))
+	}
+
+	// The whole batch is undone at once
+	if _, err = boardEditorService.Undo(ctx, fmt.Sprint(board.ID)); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := repo.GetBoardGraphByID(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Cities) != 3 || len(restored.Routes) != 1 {
+		t.Errorf("undo should have put back the original 3 cities and their route, got %+v", restored)
+	}
+	for _, city := range restored.Cities {
+		if city.ID == kept.ID && city.Name != kept.Name {
+			t.Errorf("undo should have renamed the city back to %q, was %q", kept.Name, city.Name)
+		}
+	}
+
+	// A stale version rolls back the whole batch
+	w = batch(fmt.Sprintf(`{"operations": [
+		{"op": "create", "id": -1, "city": {"name": "Bremen"}},
+		{"op": "update", "id": %d, "version": 1, "city": {"name": "Stale"}}
+	]}`, kept.ID))
+	if w.Code != http.StatusConflict {
+		t.Error("expected 409 Conflict for a stale city but got", w.Code)
+	}
+	cities, err = repo.ListCitiesByBoardID(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cities) != 3 {
+		t.Errorf("no part of a failed batch should be applied, but the board has %d cities", len(cities))
+	}
+
+	w = batch(`{"operations": [{"op": "update", "id": -5, "city": {"name": "Nowhere"}}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected 400 Bad Request for an unknown temporary ID but got", w.Code)
+	}
+
+	otherBoard := createTestBoard(ctx)
+	otherCity := createTestCity(ctx, otherBoard.ID)
+	w = batch(fmt.Sprintf(`{"operations": [{"op": "delete", "id": %d}]}`, otherCity.ID))
+	httpassert.NotFound(t, w)
+}
+
+func TestCityPlacementWarnings(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	first, err := boardEditorService.CreateCity(ctx, fmt.Sprint(board.ID), &app.CityForm{
+		Name:     "Lübeck",
+		Position: app.Position{X: 100, Y: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type cityResponse struct {
+		app.City
+		Warnings []app.CityWarning `json:"warnings"`
+	}
+	send := func(method string, url string, form app.CityForm) cityResponse {
+		body, err := json.Marshal(form)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(method, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if !httpassert.Success(t, w) {
+			t.Fatal("Body:", w.Body)
+		}
+
+		var response cityResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	created := send("POST", fmt.Sprintf("/boards/%d/cities/", board.ID), app.CityForm{
+		Name:     "Hamburg",
+		Position: app.Position{X: 110, Y: 110},
+	})
+	if created.ID == 0 || created.Name != "Hamburg" {
+		t.Errorf("response should include the saved city, got %+v", created.City)
+	}
+	if len(created.Warnings) != 1 || created.Warnings[0].Kind != app.CityWarningOverlap || created.Warnings[0].OtherCityID != first.ID {
+		t.Errorf("expected a warning about overlapping %d, got %+v", first.ID, created.Warnings)
+	}
+
+	updated := send("PUT", fmt.Sprintf("/boards/%d/cities/%d", board.ID, created.ID), app.CityForm{
+		Name:     "Hamburg",
+		Position: app.Position{X: board.Width + 10, Y: 300},
+	})
+	if len(updated.Warnings) != 1 || updated.Warnings[0].Kind != app.CityWarningOutOfBounds {
+		t.Errorf("expected a warning about being off the board, got %+v", updated.Warnings)
+	}
+
+	updated = send("PUT", fmt.Sprintf("/boards/%d/cities/%d", board.ID, created.ID), app.CityForm{
+		Name:     "Hamburg",
+		Position: app.Position{X: 300, Y: 300},
+	})
+	if updated.Warnings == nil || len(updated.Warnings) != 0 {
+		t.Errorf("expected an empty list of warnings, got %+v", updated.Warnings)
+	}
+}
+
+func TestBoardSetupForPlayerCount(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+
+	// Only bring the route's end city into play for four or more players
+	body, err := json.Marshal(map[string]interface{}{"name": "Bremen", "minPlayers": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/boards/%d/cities/%d", board.ID, route.EndCityID), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !httpassert.Success(t, w) {
+		t.Fatal("Body:", w.Body)
+	}
+
+	setup := func(players int) app.Board {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/boards/%d/setup?players=%d", board.ID, players), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if !httpassert.Success(t, w) {
+			t.Fatal("Body:", w.Body)
+		}
+
+		var board app.Board
+		if err := json.NewDecoder(w.Body).Decode(&board); err != nil {
+			t.Fatal(err)
+		}
+		return board
+	}
+
+	if setupBoard := setup(3); len(setupBoard.Cities) != 1 || len(setupBoard.Routes) != 0 {
+		t.Errorf("expected one city and no routes for three players, got %+v", setupBoard)
+	}
+	if setupBoard := setup(4); len(setupBoard.Cities) != 2 || len(setupBoard.Routes) != 1 {
+		t.Errorf("expected two cities and the route for four players, got %+v", setupBoard)
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d/setup?players=none", board.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an invalid player count, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestUpdateCity_invalidPlayerRange(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+
+	body, err := json.Marshal(map[string]interface{}{"name": "Lübeck", "minPlayers": 4, "maxPlayers": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/boards/%d/cities/%d", board.ID, city.ID), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d, got %d. Body: %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
+
+func TestUpdateCityUpgrade(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+
+	send := func(upgrade string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(map[string]interface{}{"name": city.Name, "upgrade": upgrade})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/boards/%d/cities/%d", board.ID, city.ID), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("privilege")
+	if !httpassert.Success(t, w) {
+		t.Fatal("Body:", w.Body)
+	}
+
+	graph, err := boardEditorService.FindBoardGraphByID(ctx, fmt.Sprint(board.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Cities) != 1 || graph.Cities[0].Upgrade != app.AbilityPrivilege {
+		t.Errorf("expected the city to upgrade privilege, got %+v", graph.Cities)
+	}
+
+	if w = send("teleport"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an unknown ability, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestConnectionObjective(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	url := fmt.Sprintf("/boards/%d/connection-objective", board.ID)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"startCityId": route.StartCityID,
+		"endCityId":   route.EndCityID,
+		"awards":      []int{7, 4, 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !httpassert.Success(t, w) {
+		t.Fatal("Body:", w.Body)
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d", board.ID), nil)
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+
+	var updated app.Board
+	if err = json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.ConnectionObjective == nil || updated.ConnectionObjective.EndCityID != route.EndCityID || len(updated.ConnectionObjective.Awards) != 3 {
+		t.Errorf("expected the board JSON to include the connection objective, got %+v", updated.ConnectionObjective)
+	}
+
+	other := createTestBoard(ctx)
+	otherCity := createTestCity(ctx, other.ID)
+	body, err = json.Marshal(map[string]interface{}{
+		"startCityId": route.StartCityID,
+		"endCityId":   otherCity.ID,
+		"awards":      []int{7},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for a city on another board, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", url, nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+	if err = json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.ConnectionObjective != nil {
+		t.Errorf("expected the connection objective to be removed, got %+v", updated.ConnectionObjective)
+	}
+}
+
+func TestUpdateStartingTokens(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	first := createTestRoute(ctx, board.ID)
+	second := createTestRoute(ctx, board.ID)
+	for _, route := range []*app.Route{first, second} {
+		_, err := repo.UpdateRoute(ctx, route.ID, func(route *app.Route) (*app.Route, error) {
+			route.TavernFlag = true
+			return route, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	url := fmt.Sprintf("/boards/%d/routes/starting-tokens", board.ID)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"routeIds": []app.ID{second.ID, first.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !httpassert.Success(t, w) {
+		t.Fatal("Body:", w.Body)
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("/boards/%d/routes/", board.ID), nil)
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+
+	var routes []app.Route
+	if err = json.NewDecoder(w.Body).Decode(&routes); err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range routes {
+		expected := map[app.ID]int{first.ID: 2, second.ID: 1}[route.ID]
+		if route.StartingTokenOrder != expected {
+			t.Errorf("expected route %d to have starting token order %d, got %d", route.ID, expected, route.StartingTokenOrder)
+		}
+	}
+
+	other := createTestRoute(ctx, board.ID)
+	body, err = json.Marshal(map[string]interface{}{
+		"routeIds": []app.ID{other.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for a route without a tavern, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestPrestigeTable(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	url := fmt.Sprintf("/boards/%d/prestige-table", board.ID)
+
+	send := func(method string, body interface{}) *httptest.ResponseRecorder {
+		var reader io.Reader
+		if body != nil {
+			encoded, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader = bytes.NewReader(encoded)
+		}
+		req := httptest.NewRequest(method, url, reader)
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	httpassert.NotFound(t, send("GET", nil))
+
+	w := send("PUT", map[string]interface{}{
+		"cityId": city.ID,
+		"slots": []map[string]int{
+			{"requiredPrivilege": 1, "points": 7},
+			{"requiredPrivilege": 2, "points": 8},
+		},
+	})
+	if !httpassert.Success(t, w) {
+		t.Fatal("Body:", w.Body)
+	}
+
+	w = send("GET", nil)
+	httpassert.Success(t, w)
+	var table app.PrestigeTable
+	if err := json.NewDecoder(w.Body).Decode(&table); err != nil {
+		t.Fatal(err)
+	}
+	if table.CityID != city.ID || len(table.Slots) != 2 || table.Slots[1].Points != 8 {
+		t.Errorf("unexpected prestige table %+v", table)
+	}
+
+	w = send("PUT", map[string]interface{}{"cityId": city.ID, "slots": []map[string]int{{"requiredPrivilege": 5}}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected %d for an invalid slot, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if w = send("DELETE", nil); w.Code != http.StatusNoContent {
+		t.Errorf("expected %d, got %d", http.StatusNoContent, w.Code)
+	}
+	httpassert.NotFound(t, send("GET", nil))
+}
+
+func TestListCitiesByBoardId_boardNotFound(t *testing.T) {
+	req := httptest.NewRequest("GET", "/boards/9999/cities/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.NotFound(t, w)
+}
+
+func TestListCitiesByBoardId(t *testing.T) {
+	board := testData.BoardWithCities
+
+	url := fmt.Sprintf("/boards/%d/cities/", board.ID)
+	req := httptest.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.Success(t, w)
+	httpassert.JsonArray(t, w)
+
+	responseJson := make([]app.City, 0, len(testData.BoardWithCitiesCities))
+	if err := json.NewDecoder(w.Body).Decode(&responseJson); err != nil {
+		panic(err)
+	}
+
+	if len(responseJson) != len(testData.BoardWithCitiesCities) {
+		t.Error("number of cities in json doesn't match number of cities in board")
+	}
+}
+
+func TestCreateCity(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	url := fmt.Sprintf("/boards/%d/cities/", board.ID)
+	city := app.CityForm{
+		Name: "Test City",
+		Position: app.Position{
+			X: 10,
+			Y: 20,
+		},
+	}
+
+	body, err := json.Marshal(&city)
+	if err != nil {
+		panic(err)
+	}
+
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonContentType(t, w)
+}
+
+func TestUpdateCity(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	url := fmt.Sprintf("/boards/%d/cities/%d", board.ID, city.ID)
+
+	newName := "New City Name"
+	newX := 123
+	newY := 432
+	form := app.CityForm{
+		Name: newName,
+		Position: app.Position{
+			X: newX,
+			Y: newY,
+		},
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonContentType(t, w)
+
+	var updatedCity app.City
+	if err = json.NewDecoder(w.Body).Decode(&updatedCity); err != nil {
+		panic(err)
+	}
+
+	if updatedCity.Name != newName {
+		t.Error("City Name was not updated")
+	}
+	if updatedCity.Position.X != newX {
+		t.Error("City Position X was not updated")
+	}
+	if updatedCity.Position.Y != newY {
+		t.Error("City Position Y was not updated")
+	}
+
+	// Saving again from the copy loaded before the update conflicts
+	req = httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, city.Version))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Response code is not 409 (is %d)", w.Code)
+		t.Log("Body:", w.Body)
+	}
+	if w.Header().Get("ETag") != fmt.Sprintf(`"%d"`, updatedCity.Version) {
+		t.Errorf("ETag should be the current version (was %s)", w.Header().Get("ETag"))
+	}
+}
+
+func TestDeleteBoard(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	space := app.CitySpace{
+		CityID:    city.ID,
+		Order:     1,
+		SpaceType: app.TraderID,
+	}
+	var err error
+	if err = repo.CreateCitySpace(ctx, &space); err != nil {
+		t.Fatalf("Error saving test space: %+v", err)
+	}
+	if city, err = repo.GetCityByID(ctx, city.ID); err != nil {
+		t.Fatalf("Error reloading city: %+v", err)
+	}
+	if len(city.CitySpaces) != 1 {
+		t.Error("CitySpaces HasMany relationship is not loading")
+	}
+
+	req := httptest.NewRequest("DELETE", fmt.Sprintf("/boards/%d", board.ID), nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JavascriptContentType(t, w)
+}
+
+func TestDeleteBoard_dryRunAndInUse(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	createTestCitySpace(ctx, route.StartCityID, 1)
+
+	req := httptest.NewRequest("DELETE", fmt.Sprintf("/boards/%d?dryRun=true", board.ID), nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	httpassert.Success(t, w)
+	httpassert.JsonObject(t, w)
+
+	var impact app.BoardDeleteImpact
+	if err := json.NewDecoder(w.Body).Decode(&impact); err != nil {
+		t.Fatal(err)
+	}
+	if impact.Cities != 2 || impact.CitySpaces != 1 || impact.Routes != 1 || !impact.Deletable {
+		t.Errorf("expected 2 cities, 1 space and 1 route to be deleted, got %+v", impact)
+	}
+	if _, err := repo.GetBoardByID(ctx, board.ID); err != nil {
+		t.Fatalf("a dry run should not delete the board: %+v", err)
+	}
+
+	version, err := boardEditorService.PublishBoard(ctx, fmt.Sprint(board.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	game := gorm_board_crud_repository.Game{Name: "Test Game", BoardVersionID: &version.ID}
+	if err = testDB.Create(&game).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	req = httptest.NewRequest("DELETE", fmt.Sprintf("/boards/%d", board.ID), nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected %d when a game is played on the board, got %d", http.StatusConflict, w.Code)
+	}
+	if _, err = repo.GetBoardByID(ctx, board.ID); err != nil {
+		t.Fatalf("the board should not have been deleted: %+v", err)
+	}
+}
+
+func TestDeleteCity(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	space := app.CitySpace{
+		CityID:    city.ID,
+		Order:     1,
+		SpaceType: app.TraderID,
+	}
+	if err := repo.CreateCitySpace(ctx, &space); err != nil {
+		t.Fatalf("Error saving test space: %+v", err)
+	}
+	var err error
+	if city, err = repo.GetCityByID(ctx, city.ID); err != nil {
+		t.Fatalf("Error reloading city: %+v", err)
+	}
+	if len(city.CitySpaces) != 1 {
+		t.Error("CitySpaces HasMany relationship is not loading")
+	}
+
+	url := fmt.Sprintf("/boards/%d/cities/%d", board.ID, city.ID)
+	req := httptest.NewRequest("DELETE", url, nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Response code is not 204 (is %d)", w.Code)
+		t.Log("Body:", w.Body)
+	}
+
+	var cities []app.City
+	if cities, err = repo.ListCitiesByBoardID(ctx, board.ID); err != nil {
+		panic(err)
+	}
+
+	//var spaces []app.CitySpace
+	//if err = repo.Find(&spaces, "city_id = ?", city.ID).Error; err != nil {
+	//	panic(err)
+	//}
+	//if len(spaces) != 0 {
+	//	t.Error("City spaces were not deleted")
+	//}
+
+	if len(cities) != 0 {
+		t.Error("City was not deleted")
+	}
+}
+
+func TestCreateCitySpace(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+
+	form := app.AddCitySpaceForm{
+		SpaceType:         app.MerchantID,
+		RequiredPrivilege: 2,
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/cities/%d/spaces/", board.ID, city.ID)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonObject(t, w)
+
+	var space app.CitySpace
+	if err = json.NewDecoder(w.Body).Decode(&space); err != nil {
+		panic(err)
+	}
+
+	if space.CityID != city.ID {
+		t.Error("CitySpace CityID does not match city")
+	}
+	if space.Order != 1 {
+		t.Errorf("CitySpace Order should be 1 (was %d)", space.Order)
+	}
+}
+
+func TestCreateCitySpace_invalid(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+
+	form := app.AddCitySpaceForm{
+		SpaceType:         app.TraderID,
+		RequiredPrivilege: 5,
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/cities/%d/spaces/", board.ID, city.ID)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Response code is not 400 (is %d)", w.Code)
+	}
+	httpassert.JsonObject(t, w)
+
+	var responseJson map[string]map[string][]string
+	if err = json.NewDecoder(w.Body).Decode(&responseJson); err != nil {
+		panic(err)
+	}
+	if _, ok := responseJson["errors"]["RequiredPrivilege"]; !ok {
+		t.Error("response did not include an error for RequiredPrivilege")
+	}
+}
 
-	//var spaces []app.CitySpace
-	//if err = repo.Find(&spaces, "city_id = ?", city.ID).Error; err != nil {
-	//	panic(err)
-	//}
-	//if len(spaces) != 0 {
-	//	t.Error("City spaces were not deleted")
-	//}
+func TestUpdateCitySpace(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	space := createTestCitySpace(ctx, city.ID, 1)
 
-	if len(cities) != 0 {
-		t.Error("City was not deleted")
+	form := app.UpdateCitySpaceForm{
+		SpaceType:         app.MerchantID,
+		RequiredPrivilege: 4,
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/cities/%d/spaces/%d", board.ID, city.ID, space.ID)
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonObject(t, w)
+
+	var updatedSpace app.CitySpace
+	if err = json.NewDecoder(w.Body).Decode(&updatedSpace); err != nil {
+		panic(err)
+	}
+
+	if updatedSpace.SpaceType != app.MerchantID {
+		t.Error("CitySpace SpaceType was not updated")
+	}
+	if updatedSpace.RequiredPrivilege != 4 {
+		t.Error("CitySpace RequiredPrivilege was not updated")
+	}
+}
+
+func TestReorderCitySpaces(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	first := createTestCitySpace(ctx, city.ID, 1)
+	second := createTestCitySpace(ctx, city.ID, 2)
+
+	form := app.ReorderCitySpacesForm{
+		SpaceIDs: []app.ID{second.ID, first.ID},
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/cities/%d/spaces/order", board.ID, city.ID)
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonArray(t, w)
+
+	var spaces []app.CitySpace
+	if err = json.NewDecoder(w.Body).Decode(&spaces); err != nil {
+		panic(err)
+	}
+
+	if len(spaces) != 2 || spaces[0].ID != second.ID || spaces[1].ID != first.ID {
+		t.Errorf("spaces were not reordered: %+v", spaces)
+	}
+}
+
+func TestDeleteCitySpace(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	city := createTestCity(ctx, board.ID)
+	space := createTestCitySpace(ctx, city.ID, 1)
+
+	url := fmt.Sprintf("/boards/%d/cities/%d/spaces/%d", board.ID, city.ID, space.ID)
+	req := httptest.NewRequest("DELETE", url, nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Response code is not 204 (is %d)", w.Code)
+		t.Log("Body:", w.Body)
+	}
+
+	spaces, err := repo.GetCitySpacesByCityID(ctx, city.ID)
+	if err != nil {
+		panic(err)
+	}
+	if len(spaces) != 0 {
+		t.Error("CitySpace was not deleted")
+	}
+}
+
+func TestListRoutesByBoardId(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	createTestRoute(ctx, board.ID)
+
+	url := fmt.Sprintf("/boards/%d/routes/", board.ID)
+	req := httptest.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	httpassert.Success(t, w)
+	httpassert.JsonArray(t, w)
+
+	var routes []app.Route
+	if err := json.NewDecoder(w.Body).Decode(&routes); err != nil {
+		panic(err)
+	}
+
+	if len(routes) != 1 {
+		t.Errorf("expected 1 route in json (was %d)", len(routes))
+	}
+}
+
+func TestCreateRoute(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	start := createTestCity(ctx, board.ID)
+	end := createTestCity(ctx, board.ID)
+
+	form := app.RouteForm{
+		StartCityID: start.ID,
+		EndCityID:   end.ID,
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/routes/", board.ID)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonObject(t, w)
+
+	var route app.Route
+	if err = json.NewDecoder(w.Body).Decode(&route); err != nil {
+		panic(err)
+	}
+
+	if route.ID == 0 {
+		t.Error("Route ID was not set")
+	}
+	if route.BoardID != board.ID {
+		t.Error("Route BoardID does not match board")
+	}
+}
+
+func TestCreateRoute_acrossBoards(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	otherBoard := createTestBoard(ctx)
+	start := createTestCity(ctx, board.ID)
+	end := createTestCity(ctx, otherBoard.ID)
+
+	form := app.RouteForm{
+		StartCityID: start.ID,
+		EndCityID:   end.ID,
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/routes/", board.ID)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Response code is not 400 (is %d)", w.Code)
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonObject(t, w)
+}
+
+func TestUpdateRoute(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+	newEnd := createTestCity(ctx, board.ID)
+
+	form := app.RouteForm{
+		StartCityID: route.StartCityID,
+		EndCityID:   newEnd.ID,
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/routes/%d", board.ID, route.ID)
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonObject(t, w)
+
+	var updatedRoute app.Route
+	if err = json.NewDecoder(w.Body).Decode(&updatedRoute); err != nil {
+		panic(err)
+	}
+
+	if updatedRoute.EndCityID != newEnd.ID {
+		t.Error("Route EndCityID was not updated")
+	}
+}
+
+func TestDeleteRoute(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+
+	url := fmt.Sprintf("/boards/%d/routes/%d", board.ID, route.ID)
+	req := httptest.NewRequest("DELETE", url, nil)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Response code is not 204 (is %d)", w.Code)
+		t.Log("Body:", w.Body)
+	}
+
+	routes, err := repo.ListRoutesByBoardID(ctx, board.ID)
+	if err != nil {
+		panic(err)
+	}
+	if len(routes) != 0 {
+		t.Error("Route was not deleted")
+	}
+}
+
+func TestUpdateRouteSpaces(t *testing.T) {
+	ctx := context.Background()
+	board := createTestBoard(ctx)
+	route := createTestRoute(ctx, board.ID)
+
+	form := app.RouteSpacesForm{
+		TavernFlag: true,
+		Spaces:     []app.RouteSpaceForm{{}, {}, {}},
+	}
+
+	body, err := json.Marshal(&form)
+	if err != nil {
+		panic(err)
+	}
+
+	url := fmt.Sprintf("/boards/%d/routes/%d/spaces", board.ID, route.ID)
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !httpassert.Success(t, w) {
+		t.Log("Body:", w.Body)
+	}
+	httpassert.JsonObject(t, w)
+
+	var updatedRoute app.Route
+	if err = json.NewDecoder(w.Body).Decode(&updatedRoute); err != nil {
+		panic(err)
+	}
+
+	if !updatedRoute.TavernFlag {
+		t.Error("Route TavernFlag was not updated")
+	}
+	if len(updatedRoute.RouteSpaces) != 3 {
+		t.Errorf("expected 3 route spaces (was %d)", len(updatedRoute.RouteSpaces))
 	}
 }
 
@@ -479,3 +2505,37 @@ func createTestCity(ctx context.Context, boardID app.ID) *app.City {
 
 	return &city
 }
+
+func createTestRoute(ctx context.Context, boardID app.ID) *app.Route {
+	start := createTestCity(ctx, boardID)
+	end := createTestCity(ctx, boardID)
+
+	route := app.Route{
+		BoardID:     boardID,
+		StartCityID: start.ID,
+		EndCityID:   end.ID,
+	}
+
+	err := repo.CreateRoute(ctx, &route)
+	if err != nil {
+		panic(err)
+	}
+
+	return &route
+}
+
+func createTestCitySpace(ctx context.Context, cityID app.ID, order int) *app.CitySpace {
+	space := app.CitySpace{
+		CityID:            cityID,
+		Order:             order,
+		SpaceType:         app.TraderID,
+		RequiredPrivilege: 1,
+	}
+
+	err := repo.CreateCitySpace(ctx, &space)
+	if err != nil {
+		panic(err)
+	}
+
+	return &space
+}
@@ -1,22 +1,28 @@
 package admin
 
 import (
+	"bytes"
 	"city-route-game/internal/app"
 	"city-route-game/util"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type BoardController struct {
 	Controller
 	boardEditorService app.BoardEditorService
+	boardEvents        app.BoardEventSubscriber
 }
 
-func NewBoardController(config ControllerConfig, service app.BoardEditorService) BoardController {
+func NewBoardController(config ControllerConfig, service app.BoardEditorService, events app.BoardEventSubscriber) BoardController {
 	return BoardController{
 		Controller: Controller{
 			FormDecoder:  config.FormDecoder,
@@ -24,16 +30,96 @@ func NewBoardController(config ControllerConfig, service app.BoardEditorService)
 			AssetHost:    config.AssetHost,
 		},
 		boardEditorService: service,
+		boardEvents:        events,
 	}
 }
 
+// BoardIndex The boards listed on the index page, along with the search that found them
+type BoardIndex struct {
+	Search *app.BoardSearchForm
+	*app.BoardPage
+}
+
+// SortURL Link to the listing sorted by the field, reversing the order if it is already sorted by it
+func (i BoardIndex) SortURL(sort string) string {
+	order := ""
+	if i.Search.Sort == sort || (i.Search.Sort == "" && app.BoardSort(sort) == app.BoardSortName) {
+		if i.Search.Order != "desc" {
+			order = "desc"
+		}
+	}
+	return i.url(sort, order, 1)
+}
+
+// PreviousPageURL Link to the page before this one, keeping the search and sort
+func (i BoardIndex) PreviousPageURL() string {
+	return i.url(i.Search.Sort, i.Search.Order, i.Page-1)
+}
+
+// NextPageURL Link to the page after this one, keeping the search and sort
+func (i BoardIndex) NextPageURL() string {
+	return i.url(i.Search.Sort, i.Search.Order, i.Page+1)
+}
+
+func (i BoardIndex) url(sort, order string, page int) string {
+	query := url.Values{}
+	if i.Search.Query != "" {
+		query.Set("q", i.Search.Query)
+	}
+	if sort != "" {
+		query.Set("sort", sort)
+	}
+	if order != "" {
+		query.Set("order", order)
+	}
+	if page > 1 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if i.Search.PerPage != "" {
+		query.Set("perPage", i.Search.PerPage)
+	}
+	if len(query) == 0 {
+		return "/boards/"
+	}
+	return "/boards/?" + query.Encode()
+}
+
+// Index List the boards, optionally searching their names ("q"), sorting them by "name", "created" or "updated"
+// ("sort" and "order", which is "asc" or "desc"), and paging through them ("page" and "perPage")
 func (c BoardController)Index(w http.ResponseWriter, r *http.Request) {
-	boards, err := c.boardEditorService.FindAll(r.Context())
+	query := r.URL.Query()
+	form := app.BoardSearchForm{
+		Query:   query.Get("q"),
+		Sort:    query.Get("sort"),
+		Order:   query.Get("order"),
+		Page:    query.Get("page"),
+		PerPage: query.Get("perPage"),
+	}
+	respondWithJson := strings.HasPrefix(r.Header.Get("Accept"), "application/json")
+
+	boards, err := c.boardEditorService.SearchBoards(r.Context(), &form)
 	if err != nil {
-		panic(err)
+		if !errors.Is(err, app.ErrInvalidForm) {
+			c.HandleServiceError(err, w, r)
+			return
+		}
+		if respondWithJson {
+			c.InvalidFormJSON(form.Errors, w, r)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		boards = &app.BoardPage{Boards: []app.Board{}}
+	}
+
+	if respondWithJson {
+		util.MustReturnJson(w, boards)
+		return
 	}
 
-	page := NewPageWithData(c.AssetHost, boards)
+	page := NewPageWithData(c.AssetHost, &BoardIndex{
+		Search:    &form,
+		BoardPage: boards,
+	})
 
 	if err = c.ParseAndExecuteAdminTemplate(w, "boards/index", &page); err != nil {
 		panic(err)
@@ -93,6 +179,7 @@ func (c BoardController)GetById(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Header.Get("Accept") == "application/json" {
+		util.SetETag(w, board.Version)
 		util.MustReturnJson(w, board)
 	} else {
 		page := NewPageWithData(c.AssetHost, &board)
@@ -103,6 +190,29 @@ func (c BoardController)GetById(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetSVG Draw the board with all of its cities and routes as an SVG image
+func (c BoardController)GetSVG(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	board, err := c.boardEditorService.FindBoardGraphByID(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	var svg bytes.Buffer
+	if err = RenderBoardSVG(&svg, board); err != nil {
+		c.InternalServerError(err, w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if _, err = svg.WriteTo(w); err != nil {
+		panic(err)
+	}
+}
+
 type EditBoardPage struct {
 	BoardForm *app.UpdateBoardForm
 	BoardJSON string
@@ -167,7 +277,13 @@ func (c BoardController)Update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	var err error
+	expectedVersion, err := util.ParseIfMatch(r)
+	if err != nil {
+		c.InvalidFormJSON(map[string][]string{"If-Match": {err.Error()}}, w, r)
+		return
+	}
+	form.ExpectedVersion = expectedVersion
+
 	var board *app.Board
 
 	if gotName && gotDimensions {
@@ -206,6 +322,13 @@ func (c BoardController)Update(w http.ResponseWriter, r *http.Request) {
 					panic(err)
 				}
 			}
+		} else if errors.Is(app.StaleRecord{}, err) {
+			current, err := c.boardEditorService.FindByID(r.Context(), id)
+			if err != nil {
+				c.HandleServiceError(err, w, r)
+				return
+			}
+			c.StaleRecordJSON("board", current, current.Version, w, r)
 		} else {
 			c.HandleServiceError(err, w, r)
 		}
@@ -214,6 +337,7 @@ func (c BoardController)Update(w http.ResponseWriter, r *http.Request) {
 
 	if respondWithJson {
 		util.SetJSONContentType(w)
+		util.SetETag(w, board.Version)
 		util.MustEncode(w, board)
 	} else {
 		// Call a global function in the admin js directly
@@ -225,14 +349,426 @@ func (c BoardController)Update(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Delete Remove the board and everything on it. With "dryRun=true", only report what would be removed.
 func (c BoardController)Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if r.URL.Query().Get("dryRun") == "true" {
+		impact, err := c.boardEditorService.PreviewDeleteByID(r.Context(), id)
+		if err != nil {
+			c.HandleServiceError(err, w, r)
+			return
+		}
+		util.MustReturnJson(w, impact)
+		return
+	}
+
 	if err := c.boardEditorService.DeleteByID(r.Context(), id); err != nil {
+		if errors.Is(app.BoardInUse{}, err) {
+			c.ConflictJSON(map[string][]string{"Board": {err.Error()}}, w, r)
+			return
+		}
 		c.HandleServiceError(err, w, r)
 		return
 	}
 
 	util.TurbolinksVisit("/boards", true, w, r)
 }
+
+// Validation Report whether the board is ready to be played, as JSON
+func (c BoardController)Validation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	report, err := c.boardEditorService.ValidateBoard(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, report)
+}
+
+// Stats Count up the board's design metrics, such as its offices, route lengths and graph diameter
+func (c BoardController)Stats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	stats, err := c.boardEditorService.FindBoardStats(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, stats)
+}
+
+// Diff Compare the board against another board ("against") or a published version ("version"),
+// as JSON or as a page for reviewing changes
+func (c BoardController)Diff(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	form := app.BoardDiffForm{
+		Against: r.URL.Query().Get("against"),
+		Version: r.URL.Query().Get("version"),
+	}
+
+	diff, err := c.boardEditorService.DiffBoards(r.Context(), id, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Accept"), "application/json") {
+		util.MustReturnJson(w, diff)
+		return
+	}
+
+	page := NewPageWithData(c.AssetHost, diff)
+	if err = c.ParseAndExecuteAdminTemplate(w, "boards/diff", &page); err != nil {
+		panic(err)
+	}
+}
+
+// Export Download the board and everything on it as a versioned JSON document
+func (c BoardController)Export(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	doc, err := c.boardEditorService.ExportBoard(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="board-%s.json"`, id))
+	util.MustReturnJson(w, doc)
+}
+
+// Setup The board with only the cities and routes in play for the number of players given by the "players" query parameter, as JSON
+func (c BoardController)Setup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	board, err := c.boardEditorService.FindBoardForPlayerCount(r.Context(), id, r.URL.Query().Get("players"))
+	if err != nil {
+		if errors.Is(err, app.ErrUnsupportedPlayerCount) {
+			c.InvalidFormJSON(map[string][]string{"Players": {err.Error()}}, w, r)
+			return
+		}
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, board)
+}
+
+// Import Create a new board from a JSON document produced by Export.
+// The "name" query parameter may be used to import under a different name.
+func (c BoardController)Import(w http.ResponseWriter, r *http.Request) {
+	form := app.ImportBoardForm{
+		Name: r.URL.Query().Get("name"),
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&form.Document); err != nil {
+		form.AddError("Document", "is not valid JSON: "+err.Error())
+		c.InvalidFormJSON(form.Errors, w, r)
+		return
+	}
+
+	board, err := c.boardEditorService.ImportBoard(r.Context(), &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, board)
+}
+
+// Duplicate Copy a board and everything on it under the name given in the JSON body
+func (c BoardController)Duplicate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var form app.DuplicateBoardForm
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&form); err != nil {
+		panic(err)
+	}
+
+	board, err := c.boardEditorService.DuplicateBoard(r.Context(), id, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, board)
+}
+
+// Layout Move the board's cities to positions computed from its routes.
+// The JSON body is optional; {"dryRun": true} responds with the positions without saving them.
+func (c BoardController)Layout(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var form app.LayoutBoardForm
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&form); err != nil && err != io.EOF {
+		panic(err)
+	}
+
+	layout, err := c.boardEditorService.LayoutCities(r.Context(), id, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, layout)
+}
+
+// UpdateConnectionObjective Set the board's connection objective from JSON, responding with the updated board
+func (c BoardController)UpdateConnectionObjective(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var form app.ConnectionObjectiveForm
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&form); err != nil {
+		panic(err)
+	}
+
+	board, err := c.boardEditorService.UpdateConnectionObjective(r.Context(), id, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.SetETag(w, board.Version)
+	util.MustReturnJson(w, board)
+}
+
+func (c BoardController)DeleteConnectionObjective(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	board, err := c.boardEditorService.DeleteConnectionObjective(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.SetETag(w, board.Version)
+	util.MustReturnJson(w, board)
+}
+
+func (c BoardController)PrestigeTable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	table, err := c.boardEditorService.FindPrestigeTable(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, table)
+}
+
+// UpdatePrestigeTable Create or replace the board's prestige table from JSON
+func (c BoardController)UpdatePrestigeTable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var form app.PrestigeTableForm
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&form); err != nil {
+		panic(err)
+	}
+
+	table, err := c.boardEditorService.UpdatePrestigeTable(r.Context(), id, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, table)
+}
+
+func (c BoardController)DeletePrestigeTable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := c.boardEditorService.DeletePrestigeTable(r.Context(), id); err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Publish Freeze the board as it is now into a new numbered version
+func (c BoardController)Publish(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, err := c.boardEditorService.PublishBoard(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, version)
+}
+
+func (c BoardController)Versions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	versions, err := c.boardEditorService.ListBoardVersions(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, versions)
+}
+
+func (c BoardController)Version(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	number := vars["number"]
+
+	version, err := c.boardEditorService.FindBoardVersion(r.Context(), id, number)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, version)
+}
+
+// Undo Reverse the last change made to the board in the editor, responding with the whole board
+func (c BoardController)Undo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	board, err := c.boardEditorService.Undo(r.Context(), id)
+	if err != nil {
+		c.handleHistoryError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, board)
+}
+
+// Redo Make the last undone change to the board again, responding with the whole board
+func (c BoardController)Redo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	board, err := c.boardEditorService.Redo(r.Context(), id)
+	if err != nil {
+		c.handleHistoryError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, board)
+}
+
+// handleHistoryError Respond with 409 Conflict when the board's history can't be moved in the requested
+// direction, or the change can't be reversed because of something that has changed since
+func (c BoardController)handleHistoryError(err error, w http.ResponseWriter, r *http.Request) {
+	if errors.Is(err, app.ErrNothingToUndo) || errors.Is(err, app.ErrNothingToRedo) || errors.Is(err, app.ErrNameTaken) {
+		c.ConflictJSON(map[string][]string{"Board": {err.Error()}}, w, r)
+		return
+	}
+
+	c.HandleServiceError(err, w, r)
+}
+
+// eventStreamKeepAlive How often to send a comment down an idle event stream, so proxies don't close it
+const eventStreamKeepAlive = 30 * time.Second
+
+// Events Stream every change made to the board, by anyone, as Server-Sent Events until the client disconnects.
+// Each event is named after its app.BoardEventType, with the event's data as JSON.
+func (c BoardController)Events(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	board, err := c.boardEditorService.FindByID(r.Context(), id)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		c.InternalServerError(errors.New("response writer does not support streaming"), w, r)
+		return
+	}
+
+	events, unsubscribe := c.boardEvents.Subscribe(board.ID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				panic(err)
+			}
+			if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"bytes"
+	"city-route-game/internal/app"
+	"city-route-game/util"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type BoardImageController struct {
+	Controller
+	boardImageService app.BoardImageService
+}
+
+func NewBoardImageController(config ControllerConfig, service app.BoardImageService) BoardImageController {
+	return BoardImageController{
+		Controller: Controller{
+			FormDecoder:  config.FormDecoder,
+			TemplateRoot: config.TemplateRoot,
+			AssetHost:    config.AssetHost,
+		},
+		boardImageService: service,
+	}
+}
+
+// Show Send the board's background image. Browsers may keep it, but must check that it hasn't been replaced before using it again.
+func (c BoardImageController)Show(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["id"]
+
+	image, err := c.boardImageService.FindBoardImage(r.Context(), boardId)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", image.ContentType)
+	w.Header().Set("Cache-Control", "public, no-cache")
+	w.Header().Set("ETag", fmt.Sprintf("%q", image.Checksum))
+	http.ServeContent(w, r, "", image.UpdatedAt, bytes.NewReader(image.Data))
+}
+
+// Upload Replace the board's background image with the "image" file of a multipart form.
+// If "resizeBoard" is true, the board is also resized to match the image.
+func (c BoardImageController)Upload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["id"]
+
+	var form app.BoardImageForm
+
+	file, _, err := r.FormFile("image")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		c.InvalidFormJSON(map[string][]string{"Image": {err.Error()}}, w, r)
+		return
+	}
+	if file != nil {
+		defer file.Close()
+
+		// Read one byte past the limit so the service can tell the image is too large
+		form.Data, err = ioutil.ReadAll(io.LimitReader(file, app.MaxBoardImageSize+1))
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if resizeBoard := r.FormValue("resizeBoard"); resizeBoard != "" {
+		form.ResizeBoard, err = strconv.ParseBool(resizeBoard)
+		if err != nil {
+			c.InvalidFormJSON(map[string][]string{"ResizeBoard": {"must be true or false"}}, w, r)
+			return
+		}
+	}
+
+	image, err := c.boardImageService.UploadBoardImage(r.Context(), boardId, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+			return
+		}
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, image)
+}
+
+func (c BoardImageController)Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["id"]
+
+	if err := c.boardImageService.DeleteBoardImage(r.Context(), boardId); err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
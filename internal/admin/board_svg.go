@@ -0,0 +1,158 @@
+package admin
+
+import (
+	"bufio"
+	"city-route-game/internal/app"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Dimensions and colors used when drawing boards as SVG
+const (
+	svgOfficeSize      = app.CityOfficeSize
+	svgOfficeGap       = app.CityOfficeGap
+	svgCityPadding     = app.CityPadding
+	svgRouteSpaceSize  = 7
+	svgFontSize        = 12
+	svgMinBoardSize    = 100
+	svgBackground      = "#f5efe0"
+	svgRouteColor      = "#8a6d3b"
+	svgCityFill        = "#fffaf0"
+	svgCityStroke      = "#333333"
+	svgTraderColor     = "#e8c872"
+	svgMerchantColor   = "#7fb3d5"
+	svgUnknownColor    = "#cccccc"
+	svgTavernFlagColor = "#b03a2e"
+)
+
+func svgSpaceColor(spaceType app.TradesmanType) string {
+	switch spaceType {
+	case app.TraderID:
+		return svgTraderColor
+	case app.MerchantID:
+		return svgMerchantColor
+	default:
+		return svgUnknownColor
+	}
+}
+
+func svgEscape(s string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(s))
+	return escaped.String()
+}
+
+// RenderBoardSVG Draw a board loaded with all of its cities, routes and spaces as a standalone SVG document.
+// City positions are the centers of the cities. Traders' offices are drawn as squares and merchants'
+// offices as circles, each labeled with the privilege required to claim it. Routes are lines between
+// cities with a dot for each space, and routes with a tavern flag are marked with a red diamond.
+func RenderBoardSVG(w io.Writer, board *app.Board) error {
+	out := bufio.NewWriter(w)
+
+	width, height := board.Width, board.Height
+	if width < svgMinBoardSize {
+		width = svgMinBoardSize
+	}
+	if height < svgMinBoardSize {
+		height = svgMinBoardSize
+	}
+
+	cities := make(map[app.ID]*app.City, len(board.Cities))
+	for i := range board.Cities {
+		cities[board.Cities[i].ID] = &board.Cities[i]
+	}
+
+	fmt.Fprintf(out, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%d">`+"\n",
+		width, height, width, height, svgFontSize)
+	fmt.Fprintf(out, "<title>%s</title>\n", svgEscape(board.Name))
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="%s"/>`+"\n", width, height, svgBackground)
+
+	// Routes go underneath cities
+	fmt.Fprintln(out, `<g class="routes">`)
+	for _, route := range board.Routes {
+		start, startFound := cities[route.StartCityID]
+		end, endFound := cities[route.EndCityID]
+		if !startFound || !endFound {
+			continue
+		}
+		renderRoute(out, &route, start, end)
+	}
+	fmt.Fprintln(out, `</g>`)
+
+	fmt.Fprintln(out, `<g class="cities">`)
+	for i := range board.Cities {
+		renderCity(out, &board.Cities[i])
+	}
+	fmt.Fprintln(out, `</g>`)
+
+	fmt.Fprintln(out, `</svg>`)
+
+	return out.Flush()
+}
+
+func renderRoute(out io.Writer, route *app.Route, start, end *app.City) {
+	x1, y1 := float64(start.Position.X), float64(start.Position.Y)
+	x2, y2 := float64(end.Position.X), float64(end.Position.Y)
+
+	fmt.Fprintf(out, `<g class="route" data-id="%d">`+"\n", route.ID)
+	fmt.Fprintf(out, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="3"/>`+"\n",
+		x1, y1, x2, y2, svgRouteColor)
+
+	// Spaces are spread evenly between the two cities
+	spaces := len(route.RouteSpaces)
+	for i := 0; i < spaces; i++ {
+		t := float64(i+1) / float64(spaces+1)
+		fmt.Fprintf(out, `<circle cx="%.1f" cy="%.1f" r="%d" fill="%s" stroke="%s" stroke-width="2"/>`+"\n",
+			x1+(x2-x1)*t, y1+(y2-y1)*t, svgRouteSpaceSize, svgCityFill, svgRouteColor)
+	}
+
+	if route.TavernFlag {
+		mx, my := (x1+x2)/2, (y1+y2)/2
+		if spaces%2 == 1 {
+			// Don't cover the middle space
+			my -= 2 * svgRouteSpaceSize
+		}
+		fmt.Fprintf(out, `<path d="M %.1f %.1f l 6 6 l -6 6 l -6 -6 z" fill="%s"/>`+"\n",
+			mx, my-6, svgTavernFlagColor)
+	}
+	fmt.Fprintln(out, `</g>`)
+}
+
+func renderCity(out io.Writer, city *app.City) {
+	spaces := make([]app.CitySpace, len(city.CitySpaces))
+	copy(spaces, city.CitySpaces)
+	sort.SliceStable(spaces, func(i, j int) bool {
+		return spaces[i].Order < spaces[j].Order
+	})
+
+	cityWidth, cityHeight := app.CityFootprint(city)
+	left := city.Position.X - cityWidth/2
+	top := city.Position.Y - cityHeight/2
+
+	fmt.Fprintf(out, `<g class="city" data-id="%d">`+"\n", city.ID)
+	fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="%s" stroke-width="2"/>`+"\n",
+		left, top, cityWidth, cityHeight, svgCityFill, svgCityStroke)
+
+	for i, space := range spaces {
+		x := left + svgCityPadding + i*(svgOfficeSize+svgOfficeGap)
+		y := top + svgCityPadding
+		color := svgSpaceColor(space.SpaceType)
+		if space.SpaceType == app.MerchantID {
+			fmt.Fprintf(out, `<circle cx="%d" cy="%d" r="%d" fill="%s" stroke="%s"/>`+"\n",
+				x+svgOfficeSize/2, y+svgOfficeSize/2, svgOfficeSize/2, color, svgCityStroke)
+		} else {
+			fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="%s"/>`+"\n",
+				x, y, svgOfficeSize, svgOfficeSize, color, svgCityStroke)
+		}
+		fmt.Fprintf(out, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="central">%d</text>`+"\n",
+			x+svgOfficeSize/2, y+svgOfficeSize/2, space.RequiredPrivilege)
+	}
+
+	fmt.Fprintf(out, `<text x="%d" y="%d" text-anchor="middle" font-weight="bold">%s</text>`+"\n",
+		city.Position.X, top+cityHeight+svgFontSize+2, svgEscape(city.Name))
+	fmt.Fprintln(out, `</g>`)
+}
@@ -4,6 +4,7 @@ import (
 	"city-route-game/internal/app"
 	"city-route-game/util"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"net/http"
 )
@@ -37,6 +38,13 @@ func (c CityController)Index(w http.ResponseWriter, r *http.Request) {
 	util.MustReturnJson(w, cities)
 }
 
+// cityWithWarnings The JSON for a saved city, with an extra "warnings" field listing any problems
+// with where it was placed, so the editor can point them out
+type cityWithWarnings struct {
+	*app.City
+	Warnings []app.CityWarning `json:"warnings"`
+}
+
 func (c CityController)Create(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	boardId := vars["boardId"]
@@ -52,10 +60,16 @@ func (c CityController)Create(w http.ResponseWriter, r *http.Request) {
 
 	city, err := c.boardEditorService.CreateCity(r.Context(), boardId, &cityForm)
 	if err != nil {
-		c.HandleServiceError(err, w, r)
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(cityForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
 	}
 
-	util.MustReturnJson(w, &city)
+	util.SetETag(w, city.Version)
+	util.MustReturnJson(w, cityWithWarnings{City: city, Warnings: cityForm.Warnings})
 }
 
 func (c CityController)Update(w http.ResponseWriter, r *http.Request) {
@@ -71,13 +85,32 @@ func (c CityController)Update(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
+	expectedVersion, err := util.ParseIfMatch(r)
+	if err != nil {
+		c.InvalidFormJSON(map[string][]string{"If-Match": {err.Error()}}, w, r)
+		return
+	}
+	cityForm.ExpectedVersion = expectedVersion
+
 	updatedCity, err := c.boardEditorService.UpdateCity(r.Context(), cityId, &cityForm)
 	if err != nil {
-		c.HandleServiceError(err, w, r)
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(cityForm.Errors, w, r)
+		} else if errors.Is(app.StaleRecord{}, err) {
+			current, err := c.boardEditorService.FindCityByID(r.Context(), cityId)
+			if err != nil {
+				c.HandleServiceError(err, w, r)
+				return
+			}
+			c.StaleRecordJSON("city", current, current.Version, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
 		return
 	}
 
-	util.MustReturnJson(w, updatedCity)
+	util.SetETag(w, updatedCity.Version)
+	util.MustReturnJson(w, cityWithWarnings{City: updatedCity, Warnings: cityForm.Warnings})
 }
 
 func (c CityController)Delete(w http.ResponseWriter, r *http.Request) {
@@ -93,3 +126,135 @@ func (c CityController)Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Batch Create, update, and delete many cities at once, such as when several are dragged together.
+// Either every operation is applied or none are.
+func (c CityController)Batch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["boardId"]
+
+	var form app.CityBatchForm
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&form); err != nil {
+		panic(err)
+	}
+
+	result, err := c.boardEditorService.ApplyCityBatch(r.Context(), boardId, &form)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(form.Errors, w, r)
+		} else if errors.Is(app.StaleRecord{}, err) {
+			c.ConflictJSON(map[string][]string{"Version": {err.Error()}}, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, result)
+}
+
+func (c CityController)IndexSpaces(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cityId := vars["cityId"]
+
+	city, err := c.boardEditorService.FindCityByID(r.Context(), cityId)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, city.CitySpaces)
+}
+
+func (c CityController)CreateSpace(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cityId := vars["cityId"]
+
+	var spaceForm app.AddCitySpaceForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spaceForm); err != nil {
+		panic(err)
+	}
+
+	space, err := c.boardEditorService.AddCitySpace(r.Context(), cityId, &spaceForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(spaceForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, space)
+}
+
+func (c CityController)UpdateSpace(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	//cityId := vars["cityId"]
+	spaceId := vars["id"]
+
+	var spaceForm app.UpdateCitySpaceForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spaceForm); err != nil {
+		panic(err)
+	}
+
+	space, err := c.boardEditorService.UpdateCitySpace(r.Context(), spaceId, &spaceForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(spaceForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, space)
+}
+
+// ReorderSpaces Renumber all of the city's spaces in the order posted by the board editor
+func (c CityController)ReorderSpaces(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cityId := vars["cityId"]
+
+	var reorderForm app.ReorderCitySpacesForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&reorderForm); err != nil {
+		panic(err)
+	}
+
+	spaces, err := c.boardEditorService.ReorderCitySpaces(r.Context(), cityId, &reorderForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(reorderForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, spaces)
+}
+
+func (c CityController)DeleteSpace(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	//cityId := vars["cityId"]
+	spaceId := vars["id"]
+
+	err := c.boardEditorService.DeleteCitySpace(r.Context(), spaceId)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -76,3 +76,35 @@ func (c Controller)HandleServiceError(err error, w http.ResponseWriter, r *http.
 		c.InternalServerError(err, w, r)
 	}
 }
+
+// InvalidFormJSON Respond with 400 Bad Request and the form's errors, for JSON clients such as the board editor
+func (c Controller)InvalidFormJSON(formErrors map[string][]string, w http.ResponseWriter, r *http.Request) {
+	body := make(map[string]interface{})
+	body["errors"] = formErrors
+
+	util.SetJSONContentType(w)
+	w.WriteHeader(http.StatusBadRequest)
+	util.MustEncode(w, body)
+}
+
+// ConflictJSON Respond with 409 Conflict and errors explaining why the request can't be done to things as they are now
+func (c Controller)ConflictJSON(formErrors map[string][]string, w http.ResponseWriter, r *http.Request) {
+	body := make(map[string]interface{})
+	body["errors"] = formErrors
+
+	util.SetJSONContentType(w)
+	w.WriteHeader(http.StatusConflict)
+	util.MustEncode(w, body)
+}
+
+// StaleRecordJSON Respond with 409 Conflict and the record as it is now, so the client can merge their changes and retry
+func (c Controller)StaleRecordJSON(key string, current interface{}, version int, w http.ResponseWriter, r *http.Request) {
+	body := make(map[string]interface{})
+	body[key] = current
+	body["errors"] = map[string][]string{"Version": {"has been changed by someone else"}}
+
+	util.SetJSONContentType(w)
+	util.SetETag(w, version)
+	w.WriteHeader(http.StatusConflict)
+	util.MustEncode(w, body)
+}
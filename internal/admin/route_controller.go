@@ -0,0 +1,157 @@
+package admin
+
+import (
+	"city-route-game/internal/app"
+	"city-route-game/util"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type RouteController struct {
+	Controller
+	boardEditorService app.BoardEditorService
+}
+
+func NewRouteController(config ControllerConfig, service app.BoardEditorService) RouteController {
+	return RouteController{
+		Controller{
+			FormDecoder:  config.FormDecoder,
+			TemplateRoot: config.TemplateRoot,
+			AssetHost:    config.AssetHost,
+		},
+		service,
+	}
+}
+
+func (c RouteController)Index(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["boardId"]
+
+	routes, err := c.boardEditorService.ListRoutesByBoardID(r.Context(), boardId)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	util.MustReturnJson(w, routes)
+}
+
+func (c RouteController)Create(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["boardId"]
+
+	var routeForm app.RouteForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&routeForm); err != nil {
+		panic(err)
+	}
+
+	route, err := c.boardEditorService.CreateRoute(r.Context(), boardId, &routeForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(routeForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, route)
+}
+
+func (c RouteController)Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	//boardId := vars["boardId"]
+	routeId := vars["id"]
+
+	var routeForm app.RouteForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&routeForm); err != nil {
+		panic(err)
+	}
+
+	updatedRoute, err := c.boardEditorService.UpdateRoute(r.Context(), routeId, &routeForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(routeForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, updatedRoute)
+}
+
+func (c RouteController)Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	//boardId := vars["boardId"]
+	routeId := vars["id"]
+
+	err := c.boardEditorService.DeleteRoute(r.Context(), routeId)
+	if err != nil {
+		c.HandleServiceError(err, w, r)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateSpaces Replace the route's spaces with the complete ordering posted by the board editor
+func (c RouteController)UpdateSpaces(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	//boardId := vars["boardId"]
+	routeId := vars["id"]
+
+	var spacesForm app.RouteSpacesForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spacesForm); err != nil {
+		panic(err)
+	}
+
+	updatedRoute, err := c.boardEditorService.UpdateRouteSpaces(r.Context(), routeId, &spacesForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(spacesForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, updatedRoute)
+}
+
+// UpdateStartingTokens Set which routes get a bonus token at setup, in the order the tokens are placed
+func (c RouteController)UpdateStartingTokens(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	boardId := vars["boardId"]
+
+	var startingTokensForm app.StartingTokenRoutesForm
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&startingTokensForm); err != nil {
+		panic(err)
+	}
+
+	routes, err := c.boardEditorService.UpdateStartingTokenRoutes(r.Context(), boardId, &startingTokensForm)
+	if err != nil {
+		if errors.Is(err, app.ErrInvalidForm) {
+			c.InvalidFormJSON(startingTokensForm.Errors, w, r)
+		} else {
+			c.HandleServiceError(err, w, r)
+		}
+		return
+	}
+
+	util.MustReturnJson(w, routes)
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func NewAdminRouter(boardController *BoardController, cityController *CityController, ipWhitelist []string, logRequests bool) *mux.Router {
+func NewAdminRouter(boardController *BoardController, boardImageController *BoardImageController, cityController *CityController, routeController *RouteController, ipWhitelist []string, logRequests bool) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 	if logRequests {
 		router.Use(middleware.RequestLogger)
@@ -29,17 +29,56 @@ func NewAdminRouter(boardController *BoardController, cityController *CityContro
 	boards.HandleFunc("/", boardController.Index).Methods("GET")
 	boards.HandleFunc("/new", boardController.New).Methods("GET")
 	boards.HandleFunc("/", boardController.Create).Methods("POST")
+	boards.HandleFunc("/import", boardController.Import).Methods("POST")
+	boards.HandleFunc("/{id:[0-9]+}.svg", boardController.GetSVG).Methods("GET")
 	boards.HandleFunc("/{id}", boardController.GetById).Methods("GET")
 	boards.HandleFunc("/{id}/edit", boardController.Edit).Methods("GET")
 	boards.HandleFunc("/{id}", boardController.Update).Methods("POST", "PATCH")
 	boards.HandleFunc("/{id}", boardController.Delete).Methods("DELETE")
+	boards.HandleFunc("/{id}/validation", boardController.Validation).Methods("GET")
+	boards.HandleFunc("/{id}/stats", boardController.Stats).Methods("GET")
+	boards.HandleFunc("/{id}/diff", boardController.Diff).Methods("GET")
+	boards.HandleFunc("/{id}/export", boardController.Export).Methods("GET")
+	boards.HandleFunc("/{id}/setup", boardController.Setup).Methods("GET")
+	boards.HandleFunc("/{id}/duplicate", boardController.Duplicate).Methods("POST")
+	boards.HandleFunc("/{id}/layout", boardController.Layout).Methods("POST")
+	boards.HandleFunc("/{id}/connection-objective", boardController.UpdateConnectionObjective).Methods("PUT")
+	boards.HandleFunc("/{id}/connection-objective", boardController.DeleteConnectionObjective).Methods("DELETE")
+	boards.HandleFunc("/{id}/prestige-table", boardController.PrestigeTable).Methods("GET")
+	boards.HandleFunc("/{id}/prestige-table", boardController.UpdatePrestigeTable).Methods("PUT")
+	boards.HandleFunc("/{id}/prestige-table", boardController.DeletePrestigeTable).Methods("DELETE")
+	boards.HandleFunc("/{id}/publish", boardController.Publish).Methods("POST")
+	boards.HandleFunc("/{id}/versions", boardController.Versions).Methods("GET")
+	boards.HandleFunc("/{id}/versions/{number}", boardController.Version).Methods("GET")
+	boards.HandleFunc("/{id}/undo", boardController.Undo).Methods("POST")
+	boards.HandleFunc("/{id}/redo", boardController.Redo).Methods("POST")
+	boards.HandleFunc("/{id}/events", boardController.Events).Methods("GET")
+	boards.HandleFunc("/{id}/background", boardImageController.Show).Methods("GET")
+	boards.HandleFunc("/{id}/background", boardImageController.Upload).Methods("POST", "PUT")
+	boards.HandleFunc("/{id}/background", boardImageController.Delete).Methods("DELETE")
 
 	cities := boards.PathPrefix("/{boardId}/cities").Subrouter()
 	cities.HandleFunc("/", cityController.Index).Methods("GET")
 	cities.HandleFunc("/", cityController.Create).Methods("POST")
+	cities.HandleFunc("/batch", cityController.Batch).Methods("POST")
 	cities.HandleFunc("/{id}", cityController.Update).Methods("PUT")
 	cities.HandleFunc("/{id}", cityController.Delete).Methods("DELETE")
 
+	spaces := cities.PathPrefix("/{cityId}/spaces").Subrouter()
+	spaces.HandleFunc("/", cityController.IndexSpaces).Methods("GET")
+	spaces.HandleFunc("/", cityController.CreateSpace).Methods("POST")
+	spaces.HandleFunc("/order", cityController.ReorderSpaces).Methods("PUT")
+	spaces.HandleFunc("/{id}", cityController.UpdateSpace).Methods("PUT")
+	spaces.HandleFunc("/{id}", cityController.DeleteSpace).Methods("DELETE")
+
+	routes := boards.PathPrefix("/{boardId}/routes").Subrouter()
+	routes.HandleFunc("/", routeController.Index).Methods("GET")
+	routes.HandleFunc("/", routeController.Create).Methods("POST")
+	routes.HandleFunc("/starting-tokens", routeController.UpdateStartingTokens).Methods("PUT")
+	routes.HandleFunc("/{id}", routeController.Update).Methods("PUT")
+	routes.HandleFunc("/{id}", routeController.Delete).Methods("DELETE")
+	routes.HandleFunc("/{id}/spaces", routeController.UpdateSpaces).Methods("PUT")
+
 	router.Handle("/{file}", http.FileServer(http.Dir("static/admin")))
 
 	return router
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ability One of the ability tracks on a PlayerBoard that a city can upgrade
+type Ability string
+
+const (
+	// AbilityNone The city doesn't upgrade any ability
+	AbilityNone      Ability = ""
+	AbilityAction    Ability = "action"
+	AbilityBank      Ability = "bank"
+	AbilityMove      Ability = "move"
+	AbilityKnowledge Ability = "knowledge"
+	AbilityCityKey   Ability = "cityKey"
+	AbilityPrivilege Ability = "privilege"
+)
+
+// Abilities Every ability a city can upgrade, in the order they appear on a PlayerBoard
+var Abilities = []Ability{
+	AbilityAction,
+	AbilityBank,
+	AbilityMove,
+	AbilityKnowledge,
+	AbilityCityKey,
+	AbilityPrivilege,
+}
+
+// IsValid Whether the ability is one of Abilities or AbilityNone
+func (a Ability) IsValid() bool {
+	if a == AbilityNone {
+		return true
+	}
+	for _, ability := range Abilities {
+		if a == ability {
+			return true
+		}
+	}
+	return false
+}
+
+// Level The player's level on the ability's track
+func (p *PlayerBoard) Level(a Ability) (*int, error) {
+	switch a {
+	case AbilityAction:
+		return &p.ActionLevel, nil
+	case AbilityBank:
+		return &p.BankLevel, nil
+	case AbilityMove:
+		return &p.MoveLevel, nil
+	case AbilityKnowledge:
+		return &p.KnowledgeLevel, nil
+	case AbilityCityKey:
+		return &p.CityKeyLevel, nil
+	case AbilityPrivilege:
+		return &p.PrivilegeLevel, nil
+	}
+	return nil, fmt.Errorf("%q is not an ability", a)
+}
+
+func validateAbility(form *Form, field string, a Ability) {
+	if !a.IsValid() {
+		names := make([]string, 0, len(Abilities))
+		for _, ability := range Abilities {
+			names = append(names, string(ability))
+		}
+		form.AddError(field, fmt.Sprintf("must be empty or one of %s", strings.Join(names, ", ")))
+	}
+}
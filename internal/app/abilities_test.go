@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestAbilityIsValid(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.ThatBool(AbilityNone.IsValid()).IsTrue()
+	for _, ability := range Abilities {
+		assert.ThatBool(ability.IsValid()).IsTrue()
+	}
+	assert.ThatBool(Ability("teleport").IsValid()).IsFalse()
+}
+
+func TestPlayerBoardLevel(t *testing.T) {
+	assert := assert.New(t)
+	playerBoard := PlayerBoard{BankLevel: 2}
+
+	level, err := playerBoard.Level(AbilityBank)
+	assert.That(err).IsNil()
+	assert.ThatInt(*level).IsEqualTo(2)
+
+	*level++
+	assert.ThatInt(playerBoard.BankLevel).IsEqualTo(3)
+
+	_, err = playerBoard.Level(AbilityNone)
+	assert.ThatBool(err != nil).IsTrue()
+}
+
+func TestUpdateCityUpgrade(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}}},
+		SingletonCityResult: &City{
+			Model:   Model{ID: 3},
+			BoardID: 1,
+			Name:    "Lübeck",
+			Upgrade: AbilityBank,
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	// Leaving the upgrade out of the form keeps it
+	city, err := service.UpdateCity(context.Background(), "3", &CityForm{Name: "Lübeck", Position: Position{X: 10}})
+	assert.That(err).IsNil()
+	assert.That(city.Upgrade).IsEqualTo(AbilityBank)
+
+	invalid := Ability("teleport")
+	form := CityForm{Name: "Lübeck", Upgrade: &invalid}
+	_, err = service.UpdateCity(context.Background(), "3", &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+	assert.ThatInt(len(form.Errors["Upgrade"])).IsEqualTo(1)
+}
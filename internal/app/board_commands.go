@@ -0,0 +1,242 @@
+package app
+
+import (
+	"context"
+	"errors"
+)
+
+// BoardCommandKind identifies the editor operation a BoardCommand records
+type BoardCommandKind string
+
+const (
+	CommandUpdateBoard  BoardCommandKind = "updateBoard"
+	CommandCreateCity   BoardCommandKind = "createCity"
+	CommandUpdateCity   BoardCommandKind = "updateCity"
+	CommandDeleteCity   BoardCommandKind = "deleteCity"
+	CommandLayoutCities BoardCommandKind = "layoutCities"
+	CommandCityBatch    BoardCommandKind = "cityBatch"
+
+	CommandAddCitySpace      BoardCommandKind = "addCitySpace"
+	CommandUpdateCitySpace   BoardCommandKind = "updateCitySpace"
+	CommandReorderCitySpaces BoardCommandKind = "reorderCitySpaces"
+	CommandDeleteCitySpace   BoardCommandKind = "deleteCitySpace"
+
+	CommandCreateRoute       BoardCommandKind = "createRoute"
+	CommandUpdateRoute       BoardCommandKind = "updateRoute"
+	CommandDeleteRoute       BoardCommandKind = "deleteRoute"
+	CommandUpdateRouteSpaces BoardCommandKind = "updateRouteSpaces"
+	CommandStartingTokens    BoardCommandKind = "startingTokens"
+
+	CommandUpdatePrestigeTable BoardCommandKind = "updatePrestigeTable"
+	CommandDeletePrestigeTable BoardCommandKind = "deletePrestigeTable"
+)
+
+// BoardCommandState The part of a board touched by a command, as it was before or after the command ran.
+// City is nil before a city is created and after it is deleted. Routes holds the routes that were
+// deleted along with a city, so they can be put back. Cities holds the positions of cities moved all at once.
+// Steps holds the state of each change made by a command that makes several, in the order they were made.
+// Route is a single route along with its spaces, and is nil before it is created and after it is deleted.
+// CitySpaces holds every space of one city, for commands that change them.
+// PrestigeTable is the table a deleted city hosted, which is deleted along with it.
+// PrestigeTableChange holds the board's own table, for commands that change it.
+type BoardCommandState struct {
+	Board      *Board              `json:"board,omitempty"`
+	City       *City               `json:"city,omitempty"`
+	Routes     []Route             `json:"routes,omitempty"`
+	Cities     []City              `json:"cities,omitempty"`
+	Steps      []BoardCommandState `json:"steps,omitempty"`
+	Route      *Route              `json:"route,omitempty"`
+	CitySpaces *CitySpacesState    `json:"citySpaces,omitempty"`
+
+	PrestigeTable       *PrestigeTable      `json:"prestigeTable,omitempty"`
+	PrestigeTableChange *PrestigeTableState `json:"prestigeTableChange,omitempty"`
+}
+
+// PrestigeTableState The board's prestige table, which is nil when the board has none
+type PrestigeTableState struct {
+	Table *PrestigeTable `json:"table"`
+}
+
+// CitySpacesState All of a city's spaces, in order
+type CitySpacesState struct {
+	CityID ID          `json:"cityId"`
+	Spaces []CitySpace `json:"spaces"`
+}
+
+// BoardCommand A reversible change to a board made through the editor.
+// Undoing a command restores its Before state, and redoing it restores its After state.
+type BoardCommand struct {
+	Model
+	BoardID ID                `json:"boardId"`
+	Kind    BoardCommandKind  `json:"kind"`
+	Before  BoardCommandState `json:"before"`
+	After   BoardCommandState `json:"after"`
+	Undone  bool              `json:"undone"`
+}
+
+// undo Put the board back the way it was before the command
+func (c *BoardCommand) undo(ctx context.Context, repo BoardCrudRepository) error {
+	// Later steps may depend on earlier ones, so they are taken back first
+	for i := len(c.Before.Steps) - 1; i >= 0; i-- {
+		if err := c.restore(ctx, repo, c.After.Steps[i], c.Before.Steps[i]); err != nil {
+			return err
+		}
+	}
+	return c.restore(ctx, repo, c.After, c.Before)
+}
+
+// redo Make the command's changes to the board again
+func (c *BoardCommand) redo(ctx context.Context, repo BoardCrudRepository) error {
+	for i := range c.Before.Steps {
+		if err := c.restore(ctx, repo, c.Before.Steps[i], c.After.Steps[i]); err != nil {
+			return err
+		}
+	}
+	return c.restore(ctx, repo, c.Before, c.After)
+}
+
+// restore Change the board from the "from" state of the command to the "to" state
+func (c *BoardCommand) restore(ctx context.Context, repo BoardCrudRepository, from, to BoardCommandState) error {
+	if to.Board != nil {
+		_, err := repo.UpdateBoard(ctx, c.BoardID, func(board *Board) (*Board, error) {
+			board.Name = to.Board.Name
+			board.Width = to.Board.Width
+			board.Height = to.Board.Height
+			board.PlayerRange = to.Board.PlayerRange
+			board.ConnectionObjective = to.Board.ConnectionObjective.Copy()
+			return board, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, moved := range to.Cities {
+		_, err := repo.UpdateCity(ctx, moved.ID, func(city *City) (*City, error) {
+			city.Position = moved.Position
+			return city, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if to.CitySpaces != nil {
+		if err := repo.RestoreCitySpaces(ctx, to.CitySpaces.CityID, to.CitySpaces.Spaces); err != nil {
+			return err
+		}
+	}
+
+	if to.PrestigeTableChange != nil {
+		if err := c.restorePrestigeTableChange(ctx, repo, to.PrestigeTableChange.Table); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case to.Route != nil:
+		if err := repo.RestoreRoute(ctx, to.Route); err != nil {
+			return err
+		}
+	case from.Route != nil:
+		if err := repo.DeleteRouteByID(ctx, from.Route.ID); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case from.City == nil && to.City != nil:
+		if err := repo.RestoreCity(ctx, to.City, to.Routes); err != nil {
+			return err
+		}
+		if to.PrestigeTable != nil {
+			return restorePrestigeTable(ctx, repo, to.PrestigeTable)
+		}
+		return nil
+	case from.City != nil && to.City == nil:
+		return repo.DeleteCityByID(ctx, from.City.ID)
+	case from.City != nil && to.City != nil:
+		_, err := repo.UpdateCity(ctx, to.City.ID, func(city *City) (*City, error) {
+			city.Name = to.City.Name
+			city.Position = to.City.Position
+			city.PlayerRange = to.City.PlayerRange
+			city.Upgrade = to.City.Upgrade
+			return city, nil
+		})
+		return err
+	}
+
+	return nil
+}
+
+// boardFields Copy just the board's own fields, leaving out its cities and routes
+func boardFields(board *Board) *Board {
+	return &Board{
+		Model:               board.Model,
+		Name:                board.Name,
+		Width:               board.Width,
+		Height:              board.Height,
+		Version:             board.Version,
+		PlayerRange:         board.PlayerRange,
+		ConnectionObjective: board.ConnectionObjective.Copy(),
+	}
+}
+
+// restorePrestigeTable Put back a table deleted along with its city, unless the board has been given another since
+func restorePrestigeTable(ctx context.Context, repo BoardCrudRepository, table *PrestigeTable) error {
+	_, err := repo.GetPrestigeTableByBoardID(ctx, table.BoardID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(RecordNotFound{}, err) {
+		return err
+	}
+
+	return repo.SavePrestigeTable(ctx, prestigeTableFields(table))
+}
+
+// restorePrestigeTableChange Give the board exactly the table it had, or none, as a new version of the board
+func (c *BoardCommand) restorePrestigeTableChange(ctx context.Context, repo BoardCrudRepository, table *PrestigeTable) error {
+	if table != nil {
+		if err := repo.SavePrestigeTable(ctx, prestigeTableFields(table)); err != nil {
+			return err
+		}
+	} else if err := repo.DeletePrestigeTableByBoardID(ctx, c.BoardID); err != nil && !errors.Is(RecordNotFound{}, err) {
+		return err
+	}
+
+	return touchBoard(ctx, repo, c.BoardID)
+}
+
+// touchBoard Make a new version of the board for a change to something kept apart from it, such as its prestige
+// table, so clients holding the previous version must load it again before saving
+func touchBoard(ctx context.Context, repo BoardCrudRepository, id ID) error {
+	_, err := repo.UpdateBoard(ctx, id, func(board *Board) (*Board, error) {
+		return board, nil
+	})
+	return err
+}
+
+// prestigeTableFields Copy the table and its slots
+func prestigeTableFields(table *PrestigeTable) *PrestigeTable {
+	copied := *table
+	copied.Slots = make([]PrestigeSlot, len(table.Slots))
+	copy(copied.Slots, table.Slots)
+	return &copied
+}
+
+// routeFields Copy the route and its spaces
+func routeFields(route *Route) *Route {
+	copied := *route
+	copied.RouteSpaces = make([]RouteSpace, len(route.RouteSpaces))
+	copy(copied.RouteSpaces, route.RouteSpaces)
+	return &copied
+}
+
+// cityFields Copy the city and its spaces
+func cityFields(city *City) *City {
+	copied := *city
+	copied.CitySpaces = make([]CitySpace, len(city.CitySpaces))
+	copy(copied.CitySpaces, city.CitySpaces)
+	return &copied
+}
@@ -4,23 +4,79 @@ import "context"
 
 // BoardCrudRepository Repository that is capable of loading, saving, and deleting boards and board parts
 type BoardCrudRepository interface {
+	// Transaction runs fn with a repository whose every operation is part of one transaction,
+	// which is committed if fn returns nil and rolled back otherwise
+	Transaction(ctx context.Context, fn func(repo BoardCrudRepository) error) error
+
 	GetBoardByID(ctx context.Context, id ID) (*Board, error)
+	// GetBoardGraphByID loads the board with all of its cities, routes, and their spaces
+	GetBoardGraphByID(ctx context.Context, id ID) (*Board, error)
 	CreateBoard(ctx context.Context, board *Board) error
+	// CreateBoardGraph creates the board and all of its cities, routes, and spaces in a single transaction.
+	// The IDs of the given cities are only used to match up routes with their cities, and are replaced
+	// along with every other ID by those of the newly created records.
+	CreateBoardGraph(ctx context.Context, board *Board) error
 	UpdateBoard(ctx context.Context, id ID, updateFn func (board *Board) (*Board, error)) (*Board, error)
 	ListBoards(ctx context.Context) ([]Board, error)
+	// SearchBoards lists the boards matching the query, along with how many match in all
+	SearchBoards(ctx context.Context, query BoardQuery) ([]Board, int, error)
+	// DeleteBoardByID deletes the board along with everything on it and its versions.
+	// It refuses with BoardInUse if a game is played on any version of the board.
 	DeleteBoardByID(ctx context.Context, id ID) error
+	// GetBoardDeleteImpact counts what DeleteBoardByID would remove, without deleting anything
+	GetBoardDeleteImpact(ctx context.Context, id ID) (*BoardDeleteImpact, error)
 	//BoardExistsWithName(name string) (bool, error)
 	//BoardExistsWithNameAndIdNot(name string, idNot interface{}) (bool, error)
 
+	// CreateBoardVersion saves a new snapshot of the board, numbering it one after the board's latest version
+	CreateBoardVersion(ctx context.Context, version *BoardVersion) error
+	ListBoardVersionsByBoardID(ctx context.Context, boardID ID) ([]BoardVersion, error)
+	GetBoardVersionByNumber(ctx context.Context, boardID ID, number int) (*BoardVersion, error)
+
 	ListCitiesByBoardID(ctx context.Context, boardID ID) ([]City, error)
 	GetCityByID(ctx context.Context, id ID) (*City, error)
 	CreateCity(ctx context.Context, city *City) error
 	UpdateCity(ctx context.Context, id ID, updateFn func (city *City) (*City, error)) (*City, error)
 	DeleteCityByBoardIDAndCityID(ctx context.Context, boardID ID, cityID ID) error
 	DeleteCityByID(ctx context.Context, id ID) error
+	// RestoreCity recreates a deleted city under its original ID, along with its spaces
+	// and the routes (and their spaces) that were deleted with it
+	RestoreCity(ctx context.Context, city *City, routes []Route) error
 
+	GetCitySpaceByID(ctx context.Context, id ID) (*CitySpace, error)
 	CreateCitySpace(context.Context, *CitySpace) error
-	UpdateCitySpace(ctx context.Context, id ID, updateFn func (space *CitySpace) (*CitySpace, error)) error
+	UpdateCitySpace(ctx context.Context, id ID, updateFn func (space *CitySpace) (*CitySpace, error)) (*CitySpace, error)
 	GetCitySpacesByCityID(ctx context.Context, cityID ID) ([]CitySpace, error)
 	DeleteCitySpaceByID(ctx context.Context, id ID) error
+	// ReorderCitySpaces renumbers all of a city's spaces to match the order of spaceIDs
+	ReorderCitySpaces(ctx context.Context, cityID ID, spaceIDs []ID) ([]CitySpace, error)
+	// RestoreCitySpaces replaces all of the city's spaces with exactly the ones given, under their original IDs
+	RestoreCitySpaces(ctx context.Context, cityID ID, spaces []CitySpace) error
+
+	ListRoutesByBoardID(ctx context.Context, boardID ID) ([]Route, error)
+	GetRouteByID(ctx context.Context, id ID) (*Route, error)
+	CreateRoute(ctx context.Context, route *Route) error
+	UpdateRoute(ctx context.Context, id ID, updateFn func (route *Route) (*Route, error)) (*Route, error)
+	DeleteRouteByID(ctx context.Context, id ID) error
+	// UpdateRouteSpaces saves the route along with its spaces, in the order given.
+	// Spaces without an ID are created, and existing spaces missing from the list are deleted.
+	UpdateRouteSpaces(ctx context.Context, routeID ID, updateFn func (route *Route) (*Route, error)) (*Route, error)
+	// RestoreRoute saves the route exactly as given along with exactly its spaces, under their original IDs,
+	// recreating the route if it was deleted
+	RestoreRoute(ctx context.Context, route *Route) error
+
+	// GetPrestigeTableByBoardID finds the board's prestige table along with its slots, in order
+	GetPrestigeTableByBoardID(ctx context.Context, boardID ID) (*PrestigeTable, error)
+	// SavePrestigeTable creates the board's prestige table, or replaces it and all of its slots if it already has one
+	SavePrestigeTable(ctx context.Context, table *PrestigeTable) error
+	DeletePrestigeTableByBoardID(ctx context.Context, boardID ID) error
+
+	// CreateBoardCommand records a command in the board's undo history. Any commands that were undone
+	// are discarded, since they can no longer be redone once the board has changed again.
+	CreateBoardCommand(ctx context.Context, command *BoardCommand) error
+	// GetLastBoardCommand finds the most recent command on the board that has not been undone
+	GetLastBoardCommand(ctx context.Context, boardID ID) (*BoardCommand, error)
+	// GetLastUndoneBoardCommand finds the command on the board that was undone most recently
+	GetLastUndoneBoardCommand(ctx context.Context, boardID ID) (*BoardCommand, error)
+	SetBoardCommandUndone(ctx context.Context, id ID, undone bool) error
 }
@@ -0,0 +1,15 @@
+package app
+
+// BoardDeleteImpact What deleting a board would remove along with it
+type BoardDeleteImpact struct {
+	BoardID     ID  `json:"boardId"`
+	Cities      int `json:"cities"`
+	CitySpaces  int `json:"citySpaces"`
+	Routes      int `json:"routes"`
+	RouteSpaces int `json:"routeSpaces"`
+	Versions    int `json:"versions"`
+	// Games The number of games played on any version of the board. The board can't be deleted while there are any.
+	Games int `json:"games"`
+	// Deletable Whether the board can be deleted now
+	Deletable bool `json:"deletable"`
+}
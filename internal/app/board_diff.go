@@ -0,0 +1,271 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// DiffChange How a part of a board differs between the two boards being compared
+type DiffChange string
+
+const (
+	DiffAdded    DiffChange = "added"
+	DiffRemoved  DiffChange = "removed"
+	DiffModified DiffChange = "modified"
+)
+
+// FieldDiff A field whose value differs, with its value on each board
+type FieldDiff struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// CityDiff A city that was added, removed or changed. Cities are matched by name.
+type CityDiff struct {
+	Name   string      `json:"name"`
+	Change DiffChange  `json:"change"`
+	Fields []FieldDiff `json:"fields,omitempty"`
+	Spaces []SpaceDiff `json:"spaces,omitempty"`
+}
+
+// SpaceDiff An office that was added, removed or changed, by its position in the city
+type SpaceDiff struct {
+	Order  int                     `json:"order"`
+	Change DiffChange              `json:"change"`
+	Before *BoardDocumentCitySpace `json:"before,omitempty"`
+	After  *BoardDocumentCitySpace `json:"after,omitempty"`
+}
+
+// RouteDiff A route that was added, removed or changed. Routes are matched by the names of the cities they link,
+// in either direction.
+type RouteDiff struct {
+	StartCity string      `json:"startCity"`
+	EndCity   string      `json:"endCity"`
+	Change    DiffChange  `json:"change"`
+	Fields    []FieldDiff `json:"fields,omitempty"`
+}
+
+// BoardDiff The structural differences between two boards, going from the Before board to the After board
+type BoardDiff struct {
+	Before string      `json:"before"`
+	After  string      `json:"after"`
+	Fields []FieldDiff `json:"fields"`
+	Cities []CityDiff  `json:"cities"`
+	Routes []RouteDiff `json:"routes"`
+}
+
+// Empty Whether the boards have no differences
+func (d BoardDiff) Empty() bool {
+	return len(d.Fields) == 0 && len(d.Cities) == 0 && len(d.Routes) == 0
+}
+
+// BoardDiffForm What to compare a board against: another board ("against"), one of its published versions
+// ("against" and "version"), or one of the board's own published versions ("version" alone)
+type BoardDiffForm struct {
+	Form
+	Against string
+	Version string
+}
+
+func (f *BoardDiffForm) IsValid() bool {
+	if f.Against == "" && f.Version == "" {
+		f.AddError("Against", "or Version is required")
+	}
+
+	if f.Against != "" {
+		if _, err := NewIDFromString(f.Against); err != nil {
+			f.AddError("Against", "must be a board ID")
+		}
+	}
+
+	if f.Version != "" {
+		if number, err := strconv.Atoi(f.Version); err != nil || number < 1 {
+			f.AddError("Version", "must be a version number")
+		}
+	}
+
+	return !f.HasError()
+}
+
+// NewBoardDiff Compare two board documents, as exported by NewBoardDocument or saved in a BoardVersion
+func NewBoardDiff(before, after *BoardDocument) BoardDiff {
+	diff := BoardDiff{
+		Before: before.Board.Name,
+		After:  after.Board.Name,
+		Fields: make([]FieldDiff, 0),
+		Cities: make([]CityDiff, 0),
+		Routes: make([]RouteDiff, 0),
+	}
+
+	diff.Fields = appendFieldDiff(diff.Fields, "name", before.Board.Name, after.Board.Name)
+	diff.Fields = appendFieldDiff(diff.Fields, "width", before.Board.Width, after.Board.Width)
+	diff.Fields = appendFieldDiff(diff.Fields, "height", before.Board.Height, after.Board.Height)
+	diff.Fields = appendFieldDiff(diff.Fields, "minPlayers", before.Board.MinPlayers, after.Board.MinPlayers)
+	diff.Fields = appendFieldDiff(diff.Fields, "maxPlayers", before.Board.MaxPlayers, after.Board.MaxPlayers)
+
+	diff.Cities = diffCities(before.Cities, after.Cities)
+	diff.Routes = diffRoutes(before, after)
+
+	return diff
+}
+
+func appendFieldDiff(fields []FieldDiff, field string, before, after interface{}) []FieldDiff {
+	if before == after {
+		return fields
+	}
+	return append(fields, FieldDiff{Field: field, Before: before, After: after})
+}
+
+// matchByKey Pair up the items on each side that have the same key, in the order they appear.
+// Indexes without a match on the other side are -1.
+func matchByKey(beforeKeys, afterKeys []string) [][2]int {
+	unmatched := make(map[string][]int, len(beforeKeys))
+	for i, key := range beforeKeys {
+		unmatched[key] = append(unmatched[key], i)
+	}
+
+	pairs := make([][2]int, 0, len(afterKeys))
+	for j, key := range afterKeys {
+		if candidates := unmatched[key]; len(candidates) > 0 {
+			pairs = append(pairs, [2]int{candidates[0], j})
+			unmatched[key] = candidates[1:]
+		} else {
+			pairs = append(pairs, [2]int{-1, j})
+		}
+	}
+
+	removed := make([]int, 0)
+	for _, indexes := range unmatched {
+		removed = append(removed, indexes...)
+	}
+	sort.Ints(removed)
+	for _, i := range removed {
+		pairs = append(pairs, [2]int{i, -1})
+	}
+
+	return pairs
+}
+
+func diffCities(before, after []BoardDocumentCity) []CityDiff {
+	beforeNames := make([]string, 0, len(before))
+	for _, city := range before {
+		beforeNames = append(beforeNames, city.Name)
+	}
+	afterNames := make([]string, 0, len(after))
+	for _, city := range after {
+		afterNames = append(afterNames, city.Name)
+	}
+
+	cities := make([]CityDiff, 0)
+	for _, pair := range matchByKey(beforeNames, afterNames) {
+		switch {
+		case pair[0] == -1:
+			cities = append(cities, CityDiff{Name: after[pair[1]].Name, Change: DiffAdded})
+		case pair[1] == -1:
+			cities = append(cities, CityDiff{Name: before[pair[0]].Name, Change: DiffRemoved})
+		default:
+			b, a := before[pair[0]], after[pair[1]]
+			city := CityDiff{Name: a.Name, Change: DiffModified}
+			city.Fields = appendFieldDiff(city.Fields, "x", b.Position.X, a.Position.X)
+			city.Fields = appendFieldDiff(city.Fields, "y", b.Position.Y, a.Position.Y)
+			city.Fields = appendFieldDiff(city.Fields, "minPlayers", b.MinPlayers, a.MinPlayers)
+			city.Fields = appendFieldDiff(city.Fields, "maxPlayers", b.MaxPlayers, a.MaxPlayers)
+			city.Fields = appendFieldDiff(city.Fields, "upgrade", b.Upgrade, a.Upgrade)
+			city.Spaces = diffSpaces(b.Spaces, a.Spaces)
+			if len(city.Fields) > 0 || len(city.Spaces) > 0 {
+				cities = append(cities, city)
+			}
+		}
+	}
+
+	return cities
+}
+
+func diffSpaces(before, after []BoardDocumentCitySpace) []SpaceDiff {
+	var spaces []SpaceDiff
+	for i := 0; i < len(before) || i < len(after); i++ {
+		space := SpaceDiff{Order: i + 1}
+		if i < len(before) {
+			space.Before = &before[i]
+		}
+		if i < len(after) {
+			space.After = &after[i]
+		}
+
+		switch {
+		case space.Before == nil:
+			space.Change = DiffAdded
+		case space.After == nil:
+			space.Change = DiffRemoved
+		case *space.Before != *space.After:
+			space.Change = DiffModified
+		default:
+			continue
+		}
+		spaces = append(spaces, space)
+	}
+	return spaces
+}
+
+// routeEnds The names of the cities at each end of every route in the document
+func routeEnds(doc *BoardDocument) [][2]string {
+	names := make(map[string]string, len(doc.Cities))
+	for _, city := range doc.Cities {
+		names[city.Ref] = city.Name
+	}
+
+	ends := make([][2]string, 0, len(doc.Routes))
+	for _, route := range doc.Routes {
+		ends = append(ends, [2]string{names[route.StartCity], names[route.EndCity]})
+	}
+	return ends
+}
+
+func diffRoutes(before, after *BoardDocument) []RouteDiff {
+	beforeEnds := routeEnds(before)
+	afterEnds := routeEnds(after)
+
+	// Routes run both ways, so the ends are put in the same order before matching
+	key := func(ends [2]string) string {
+		if ends[1] < ends[0] {
+			ends[0], ends[1] = ends[1], ends[0]
+		}
+		return fmt.Sprintf("%q-%q", ends[0], ends[1])
+	}
+	beforeKeys := make([]string, 0, len(beforeEnds))
+	for _, ends := range beforeEnds {
+		beforeKeys = append(beforeKeys, key(ends))
+	}
+	afterKeys := make([]string, 0, len(afterEnds))
+	for _, ends := range afterEnds {
+		afterKeys = append(afterKeys, key(ends))
+	}
+
+	routes := make([]RouteDiff, 0)
+	for _, pair := range matchByKey(beforeKeys, afterKeys) {
+		switch {
+		case pair[0] == -1:
+			ends := afterEnds[pair[1]]
+			routes = append(routes, RouteDiff{StartCity: ends[0], EndCity: ends[1], Change: DiffAdded})
+		case pair[1] == -1:
+			ends := beforeEnds[pair[0]]
+			routes = append(routes, RouteDiff{StartCity: ends[0], EndCity: ends[1], Change: DiffRemoved})
+		default:
+			b, a := before.Routes[pair[0]], after.Routes[pair[1]]
+			ends := afterEnds[pair[1]]
+			route := RouteDiff{StartCity: ends[0], EndCity: ends[1], Change: DiffModified}
+			route.Fields = appendFieldDiff(route.Fields, "spaces", b.Spaces, a.Spaces)
+			route.Fields = appendFieldDiff(route.Fields, "tavernFlag", b.TavernFlag, a.TavernFlag)
+			route.Fields = appendFieldDiff(route.Fields, "minPlayers", b.MinPlayers, a.MinPlayers)
+			route.Fields = appendFieldDiff(route.Fields, "maxPlayers", b.MaxPlayers, a.MaxPlayers)
+			route.Fields = appendFieldDiff(route.Fields, "startingTokenOrder", b.StartingTokenOrder, a.StartingTokenOrder)
+			if len(route.Fields) > 0 {
+				routes = append(routes, route)
+			}
+		}
+	}
+
+	return routes
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestNewBoardDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	before := BoardDocument{
+		Board: BoardDocumentBoard{Name: "Draft", Width: 100, Height: 100},
+		Cities: []BoardDocumentCity{
+			{Ref: "a", Name: "Lübeck", Spaces: []BoardDocumentCitySpace{{SpaceType: TraderID, RequiredPrivilege: 1}}},
+			{Ref: "b", Name: "Hamburg"},
+			{Ref: "c", Name: "Bremen"},
+		},
+		Routes: []BoardDocumentRoute{
+			{StartCity: "a", EndCity: "b", Spaces: 3},
+			{StartCity: "b", EndCity: "c", Spaces: 2},
+		},
+	}
+	after := BoardDocument{
+		Board: BoardDocumentBoard{Name: "Draft", Width: 120, Height: 100},
+		Cities: []BoardDocumentCity{
+			{Ref: "city-1", Name: "Hamburg"},
+			{Ref: "city-2", Name: "Lübeck", Position: Position{X: 5}, Spaces: []BoardDocumentCitySpace{
+				{SpaceType: MerchantID, RequiredPrivilege: 1},
+				{SpaceType: TraderID, RequiredPrivilege: 2},
+			}},
+			{Ref: "city-3", Name: "Stade"},
+		},
+		Routes: []BoardDocumentRoute{
+			// Same route as before, going the other way and one space longer
+			{StartCity: "city-1", EndCity: "city-2", Spaces: 4},
+			{StartCity: "city-1", EndCity: "city-3", Spaces: 2},
+		},
+	}
+
+	diff := NewBoardDiff(&before, &after)
+	assert.That(diff.Fields).IsEqualTo([]FieldDiff{{Field: "width", Before: 100, After: 120}})
+
+	assert.ThatInt(len(diff.Cities)).IsEqualTo(3)
+	assert.ThatString(diff.Cities[0].Name).IsEqualTo("Lübeck")
+	assert.That(diff.Cities[0].Change).IsEqualTo(DiffModified)
+	assert.That(diff.Cities[0].Fields).IsEqualTo([]FieldDiff{{Field: "x", Before: 0, After: 5}})
+	assert.ThatInt(len(diff.Cities[0].Spaces)).IsEqualTo(2)
+	assert.That(diff.Cities[0].Spaces[0].Change).IsEqualTo(DiffModified)
+	assert.That(diff.Cities[0].Spaces[1].Change).IsEqualTo(DiffAdded)
+	assert.That(diff.Cities[1]).IsEqualTo(CityDiff{Name: "Stade", Change: DiffAdded})
+	assert.That(diff.Cities[2]).IsEqualTo(CityDiff{Name: "Bremen", Change: DiffRemoved})
+
+	assert.ThatInt(len(diff.Routes)).IsEqualTo(3)
+	assert.That(diff.Routes[0].Change).IsEqualTo(DiffModified)
+	assert.That(diff.Routes[0].Fields).IsEqualTo([]FieldDiff{{Field: "spaces", Before: 3, After: 4}})
+	assert.That(diff.Routes[1]).IsEqualTo(RouteDiff{StartCity: "Hamburg", EndCity: "Stade", Change: DiffAdded})
+	assert.That(diff.Routes[2]).IsEqualTo(RouteDiff{StartCity: "Hamburg", EndCity: "Bremen", Change: DiffRemoved})
+
+	assert.ThatBool(NewBoardDiff(&after, &after).Empty()).IsTrue()
+}
+
+func TestBoardDiffFormIsValid(t *testing.T) {
+	assert := assert.New(t)
+
+	form := BoardDiffForm{Against: "2"}
+	assert.ThatBool(form.IsValid()).IsTrue()
+
+	form = BoardDiffForm{Version: "3"}
+	assert.ThatBool(form.IsValid()).IsTrue()
+
+	form = BoardDiffForm{}
+	assert.ThatBool(form.IsValid()).IsFalse()
+
+	form = BoardDiffForm{Against: "two", Version: "0"}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	assert.ThatInt(len(form.Errors["Against"])).IsEqualTo(1)
+	assert.ThatInt(len(form.Errors["Version"])).IsEqualTo(1)
+}
+
+func TestDiffBoards(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{Model: Model{ID: 1}, Name: "Original", Width: 100},
+			{Model: Model{ID: 2}, Name: "Copy", Width: 120},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	form := BoardDiffForm{Against: "1"}
+	diff, err := service.DiffBoards(context.Background(), "2", &form)
+	if err != nil {
+		t.Fatalf("DiffBoards returned error: %+v", err)
+	}
+	assert.ThatString(diff.Before).IsEqualTo("Original")
+	assert.ThatString(diff.After).IsEqualTo("Copy")
+	assert.ThatInt(len(diff.Fields)).IsEqualTo(2)
+
+	form = BoardDiffForm{}
+	_, err = service.DiffBoards(context.Background(), "2", &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+}
@@ -0,0 +1,352 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BoardDocumentVersion Version of the board export format written by NewBoardDocument.
+// Only bump this for changes older importers can't read; adding optional fields doesn't need a new version.
+const BoardDocumentVersion = 1
+
+// MaxRouteSpaces The most spaces a route in a board document may have. Real boards need only a handful,
+// so anything far beyond that is a broken or malicious document.
+const MaxRouteSpaces = 20
+
+// BoardDocument Self-contained export of a board and everything on it, for moving boards between databases.
+// Cities are referred to by a Ref that is local to the document, never by database ID.
+type BoardDocument struct {
+	Version int                  `json:"version"`
+	Board   BoardDocumentBoard   `json:"board"`
+	Cities  []BoardDocumentCity  `json:"cities"`
+	Routes  []BoardDocumentRoute `json:"routes"`
+	// PrestigeTable is left out for boards without one
+	PrestigeTable *BoardDocumentPrestigeTable `json:"prestigeTable,omitempty"`
+	// ConnectionObjective is left out for boards without one
+	ConnectionObjective *BoardDocumentConnectionObjective `json:"connectionObjective,omitempty"`
+}
+
+type BoardDocumentBoard struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	PlayerRange
+}
+
+type BoardDocumentCity struct {
+	Ref      string   `json:"ref"`
+	Name     string   `json:"name"`
+	Position Position `json:"position"`
+	PlayerRange
+	Upgrade Ability                  `json:"upgrade,omitempty"`
+	Spaces  []BoardDocumentCitySpace `json:"spaces"`
+}
+
+// BoardDocumentCitySpace An office in a city. Offices are ordered by their position in the list.
+type BoardDocumentCitySpace struct {
+	SpaceType         TradesmanType `json:"spaceType"`
+	RequiredPrivilege int           `json:"requiredPrivilege"`
+}
+
+type BoardDocumentRoute struct {
+	StartCity  string `json:"startCity"`
+	EndCity    string `json:"endCity"`
+	TavernFlag bool   `json:"tavernFlag"`
+	Spaces     int    `json:"spaces"`
+	PlayerRange
+	StartingTokenOrder int `json:"startingTokenOrder,omitempty"`
+}
+
+// BoardDocumentPrestigeTable The board's prestige table, hosted by the city with the Ref City.
+// Slots are ordered by their position in the list.
+type BoardDocumentPrestigeTable struct {
+	City  string                      `json:"city"`
+	Slots []BoardDocumentPrestigeSlot `json:"slots"`
+}
+
+type BoardDocumentPrestigeSlot struct {
+	RequiredPrivilege int `json:"requiredPrivilege"`
+	Points            int `json:"points"`
+}
+
+// BoardDocumentConnectionObjective The board's connection objective between the cities with the Refs StartCity and EndCity
+type BoardDocumentConnectionObjective struct {
+	StartCity string `json:"startCity"`
+	EndCity   string `json:"endCity"`
+	Awards    []int  `json:"awards"`
+}
+
+// NewBoardDocument Export a board loaded with BoardCrudRepository.GetBoardGraphByID
+func NewBoardDocument(board *Board) BoardDocument {
+	doc := BoardDocument{
+		Version: BoardDocumentVersion,
+		Board: BoardDocumentBoard{
+			Name:        board.Name,
+			Width:       board.Width,
+			Height:      board.Height,
+			PlayerRange: board.PlayerRange,
+		},
+		Cities: make([]BoardDocumentCity, 0, len(board.Cities)),
+		Routes: make([]BoardDocumentRoute, 0, len(board.Routes)),
+	}
+
+	refs := make(map[ID]string, len(board.Cities))
+	for i, city := range board.Cities {
+		ref := fmt.Sprintf("city-%d", i+1)
+		refs[city.ID] = ref
+
+		spaces := make([]CitySpace, len(city.CitySpaces))
+		copy(spaces, city.CitySpaces)
+		sort.SliceStable(spaces, func(i, j int) bool {
+			return spaces[i].Order < spaces[j].Order
+		})
+
+		docCity := BoardDocumentCity{
+			Ref:         ref,
+			Name:        city.Name,
+			Position:    city.Position,
+			PlayerRange: city.PlayerRange,
+			Upgrade:     city.Upgrade,
+			Spaces:      make([]BoardDocumentCitySpace, 0, len(spaces)),
+		}
+		for _, space := range spaces {
+			docCity.Spaces = append(docCity.Spaces, BoardDocumentCitySpace{
+				SpaceType:         space.SpaceType,
+				RequiredPrivilege: space.RequiredPrivilege,
+			})
+		}
+		doc.Cities = append(doc.Cities, docCity)
+	}
+
+	for _, route := range board.Routes {
+		doc.Routes = append(doc.Routes, BoardDocumentRoute{
+			StartCity:          refs[route.StartCityID],
+			EndCity:            refs[route.EndCityID],
+			TavernFlag:         route.TavernFlag,
+			Spaces:             len(route.RouteSpaces),
+			PlayerRange:        route.PlayerRange,
+			StartingTokenOrder: route.StartingTokenOrder,
+		})
+	}
+
+	if board.ConnectionObjective != nil {
+		doc.ConnectionObjective = &BoardDocumentConnectionObjective{
+			StartCity: refs[board.ConnectionObjective.StartCityID],
+			EndCity:   refs[board.ConnectionObjective.EndCityID],
+			Awards:    append([]int{}, board.ConnectionObjective.Awards...),
+		}
+	}
+
+	if board.PrestigeTable != nil {
+		slots := make([]PrestigeSlot, len(board.PrestigeTable.Slots))
+		copy(slots, board.PrestigeTable.Slots)
+		sort.SliceStable(slots, func(i, j int) bool {
+			return slots[i].Order < slots[j].Order
+		})
+
+		doc.PrestigeTable = &BoardDocumentPrestigeTable{
+			City:  refs[board.PrestigeTable.CityID],
+			Slots: make([]BoardDocumentPrestigeSlot, 0, len(slots)),
+		}
+		for _, slot := range slots {
+			doc.PrestigeTable.Slots = append(doc.PrestigeTable.Slots, BoardDocumentPrestigeSlot{
+				RequiredPrivilege: slot.RequiredPrivilege,
+				Points:            slot.Points,
+			})
+		}
+	}
+
+	return doc
+}
+
+// ToBoard Convert the document into a board graph suitable for BoardCrudRepository.CreateBoardGraph.
+// Cities are given placeholder IDs (their position in the document) which routes refer to;
+// the repository replaces them with real IDs on save. Problems with the document are added
+// to the form under "Document".
+func (d *BoardDocument) ToBoard(form *Form) (*Board, error) {
+	if d.Version < 1 || d.Version > BoardDocumentVersion {
+		form.AddError("Document", fmt.Sprintf("version %d is not supported (must be between 1 and %d)", d.Version, BoardDocumentVersion))
+		return nil, ErrInvalidForm
+	}
+
+	board := Board{
+		Name:        d.Board.Name,
+		Width:       d.Board.Width,
+		Height:      d.Board.Height,
+		PlayerRange: d.Board.PlayerRange,
+		Cities:      make([]City, 0, len(d.Cities)),
+		Routes:      make([]Route, 0, len(d.Routes)),
+	}
+	validateDocumentPlayerRange(form, "board", d.Board.PlayerRange)
+
+	ids := make(map[string]ID, len(d.Cities))
+	for i, docCity := range d.Cities {
+		id := ID(i + 1)
+		if docCity.Ref == "" {
+			form.AddError("Document", fmt.Sprintf("city %d has no ref", i+1))
+		} else if _, exists := ids[docCity.Ref]; exists {
+			form.AddError("Document", fmt.Sprintf("city ref %q is used more than once", docCity.Ref))
+		}
+		ids[docCity.Ref] = id
+
+		validateDocumentPlayerRange(form, fmt.Sprintf("city %q", docCity.Ref), docCity.PlayerRange)
+		if !docCity.Upgrade.IsValid() {
+			form.AddError("Document", fmt.Sprintf("city %q upgrade %q is not an ability", docCity.Ref, docCity.Upgrade))
+		}
+
+		city := City{
+			Model:       Model{ID: id},
+			Name:        docCity.Name,
+			Position:    docCity.Position,
+			PlayerRange: docCity.PlayerRange,
+			Upgrade:     docCity.Upgrade,
+			CitySpaces:  make([]CitySpace, 0, len(docCity.Spaces)),
+		}
+		for j, docSpace := range docCity.Spaces {
+			spaceForm := AddCitySpaceForm{
+				CityID:            id,
+				SpaceType:         docSpace.SpaceType,
+				RequiredPrivilege: docSpace.RequiredPrivilege,
+			}
+			if !spaceForm.IsValid() {
+				for field, msgs := range spaceForm.Errors {
+					for _, msg := range msgs {
+						form.AddError("Document", fmt.Sprintf("city %q space %d %s %s", docCity.Ref, j+1, field, msg))
+					}
+				}
+			}
+
+			city.CitySpaces = append(city.CitySpaces, CitySpace{
+				CityID:            id,
+				Order:             j + 1,
+				SpaceType:         docSpace.SpaceType,
+				RequiredPrivilege: docSpace.RequiredPrivilege,
+			})
+		}
+		board.Cities = append(board.Cities, city)
+	}
+
+	startingTokens := make(map[int]bool)
+	for i, docRoute := range d.Routes {
+		startID, startFound := ids[docRoute.StartCity]
+		endID, endFound := ids[docRoute.EndCity]
+		if !startFound {
+			form.AddError("Document", fmt.Sprintf("route %d start city %q does not exist", i+1, docRoute.StartCity))
+		}
+		if !endFound {
+			form.AddError("Document", fmt.Sprintf("route %d end city %q does not exist", i+1, docRoute.EndCity))
+		}
+		if startFound && startID == endID {
+			form.AddError("Document", fmt.Sprintf("route %d must connect two different cities", i+1))
+		}
+		spaceCount := docRoute.Spaces
+		if spaceCount < 0 {
+			form.AddError("Document", fmt.Sprintf("route %d must not have a negative number of spaces", i+1))
+			spaceCount = 0
+		} else if spaceCount > MaxRouteSpaces {
+			form.AddError("Document", fmt.Sprintf("route %d must not have more than %d spaces", i+1, MaxRouteSpaces))
+			spaceCount = 0
+		}
+		validateDocumentPlayerRange(form, fmt.Sprintf("route %d", i+1), docRoute.PlayerRange)
+		if docRoute.StartingTokenOrder < 0 {
+			form.AddError("Document", fmt.Sprintf("route %d must not have a negative starting token order", i+1))
+		} else if docRoute.StartingTokenOrder > 0 {
+			if !docRoute.TavernFlag {
+				form.AddError("Document", fmt.Sprintf("route %d has a starting token but no tavern", i+1))
+			}
+			if startingTokens[docRoute.StartingTokenOrder] {
+				form.AddError("Document", fmt.Sprintf("route %d has the same starting token order as another route", i+1))
+			}
+			startingTokens[docRoute.StartingTokenOrder] = true
+		}
+
+		route := Route{
+			StartCityID:        startID,
+			EndCityID:          endID,
+			TavernFlag:         docRoute.TavernFlag,
+			PlayerRange:        docRoute.PlayerRange,
+			StartingTokenOrder: docRoute.StartingTokenOrder,
+			RouteSpaces:        make([]RouteSpace, 0, spaceCount),
+		}
+		for j := 0; j < spaceCount; j++ {
+			route.RouteSpaces = append(route.RouteSpaces, RouteSpace{Order: j + 1})
+		}
+		board.Routes = append(board.Routes, route)
+	}
+
+	if d.ConnectionObjective != nil {
+		objectiveForm := ConnectionObjectiveForm{Awards: d.ConnectionObjective.Awards}
+		ends := []struct {
+			name string
+			ref  string
+			id   *ID
+		}{
+			{"start", d.ConnectionObjective.StartCity, &objectiveForm.StartCityID},
+			{"end", d.ConnectionObjective.EndCity, &objectiveForm.EndCityID},
+		}
+		for _, end := range ends {
+			id, found := ids[end.ref]
+			if !found {
+				form.AddError("Document", fmt.Sprintf("connection objective %s city %q does not exist", end.name, end.ref))
+			}
+			*end.id = id
+		}
+
+		if !objectiveForm.IsValid() {
+			for field, msgs := range objectiveForm.Errors {
+				if field == "StartCityID" || (field == "EndCityID" && objectiveForm.EndCityID == 0) {
+					continue
+				}
+				for _, msg := range msgs {
+					form.AddError("Document", fmt.Sprintf("connection objective %s %s", field, msg))
+				}
+			}
+		}
+		board.ConnectionObjective = objectiveForm.ConnectionObjective()
+	}
+
+	if d.PrestigeTable != nil {
+		tableForm := PrestigeTableForm{Slots: make([]PrestigeSlotForm, 0, len(d.PrestigeTable.Slots))}
+		for _, slot := range d.PrestigeTable.Slots {
+			tableForm.Slots = append(tableForm.Slots, PrestigeSlotForm{
+				RequiredPrivilege: slot.RequiredPrivilege,
+				Points:            slot.Points,
+			})
+		}
+
+		cityID, found := ids[d.PrestigeTable.City]
+		if !found {
+			form.AddError("Document", fmt.Sprintf("prestige table city %q does not exist", d.PrestigeTable.City))
+		}
+		tableForm.CityID = cityID
+
+		if !tableForm.IsValid() {
+			for field, msgs := range tableForm.Errors {
+				if field == "CityID" {
+					continue
+				}
+				for _, msg := range msgs {
+					form.AddError("Document", fmt.Sprintf("prestige table %s %s", field, msg))
+				}
+			}
+		}
+		board.PrestigeTable = tableForm.PrestigeTable(0)
+	}
+
+	if form.HasError() {
+		return nil, ErrInvalidForm
+	}
+
+	return &board, nil
+}
+
+// validateDocumentPlayerRange Report problems with a player range under the Document field, naming what it belongs to
+func validateDocumentPlayerRange(form *Form, what string, r PlayerRange) {
+	rangeForm := Form{}
+	validatePlayerRange(&rangeForm, r)
+	for field, msgs := range rangeForm.Errors {
+		for _, msg := range msgs {
+			form.AddError("Document", fmt.Sprintf("%s %s %s", what, field, msg))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"github.com/assertgo/assert"
+	"testing"
+)
+
+func TestBoardDocumentRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	original := newPlayableTestBoard()
+
+	doc := NewBoardDocument(original)
+	assert.ThatInt(doc.Version).IsEqualTo(BoardDocumentVersion)
+	assert.ThatInt(len(doc.Cities)).IsEqualTo(2)
+	assert.ThatString(doc.Routes[0].StartCity).IsEqualTo(doc.Cities[0].Ref)
+	assert.ThatString(doc.Routes[0].EndCity).IsEqualTo(doc.Cities[1].Ref)
+	assert.ThatInt(doc.Routes[0].Spaces).IsEqualTo(1)
+
+	form := Form{}
+	board, err := doc.ToBoard(&form)
+	if err != nil {
+		t.Fatalf("ToBoard returned error: %+v %+v", err, form.Errors)
+	}
+
+	assert.ThatString(board.Name).IsEqualTo(original.Name)
+	assert.ThatInt(board.Width).IsEqualTo(original.Width)
+	assert.ThatInt(len(board.Cities)).IsEqualTo(2)
+	assert.ThatInt(len(board.Cities[0].CitySpaces)).IsEqualTo(2)
+	assert.That(board.Cities[0].CitySpaces[1].SpaceType).IsEqualTo(MerchantID)
+	assert.ThatInt(board.Cities[0].CitySpaces[1].Order).IsEqualTo(2)
+	assert.That(board.Cities[1].Position).IsEqualTo(original.Cities[1].Position)
+	assert.ThatInt(len(board.Routes)).IsEqualTo(1)
+	assert.That(board.Routes[0].StartCityID).IsEqualTo(board.Cities[0].ID)
+	assert.That(board.Routes[0].EndCityID).IsEqualTo(board.Cities[1].ID)
+	assert.ThatInt(len(board.Routes[0].RouteSpaces)).IsEqualTo(1)
+}
+
+func TestBoardDocumentToBoardRejectsUnsupportedVersion(t *testing.T) {
+	doc := NewBoardDocument(newPlayableTestBoard())
+	doc.Version = BoardDocumentVersion + 1
+
+	form := Form{}
+	if _, err := doc.ToBoard(&form); err != ErrInvalidForm {
+		t.Errorf("expected ErrInvalidForm, got: %+v", err)
+	}
+	if len(form.Errors["Document"]) != 1 {
+		t.Errorf("expected one Document error, got: %+v", form.Errors)
+	}
+}
+
+func TestBoardDocumentToBoardRejectsBadReferences(t *testing.T) {
+	doc := NewBoardDocument(newPlayableTestBoard())
+	doc.Cities[1].Ref = doc.Cities[0].Ref
+	doc.Routes = append(doc.Routes, BoardDocumentRoute{
+		StartCity: "nowhere",
+		EndCity:   doc.Cities[0].Ref,
+		Spaces:    -1,
+	})
+
+	form := Form{}
+	if _, err := doc.ToBoard(&form); err != ErrInvalidForm {
+		t.Errorf("expected ErrInvalidForm, got: %+v", err)
+	}
+	// duplicate ref, self-loop on the original route, missing start city and negative spaces
+	if len(form.Errors["Document"]) != 4 {
+		t.Errorf("expected four Document errors, got: %+v", form.Errors)
+	}
+}
+
+func TestBoardDocumentToBoardRejectsTooManyRouteSpaces(t *testing.T) {
+	doc := NewBoardDocument(newPlayableTestBoard())
+	doc.Routes[0].Spaces = 2000000000
+
+	form := Form{}
+	board, err := doc.ToBoard(&form)
+	if err != ErrInvalidForm {
+		t.Fatalf("expected ErrInvalidForm, got: %+v", err)
+	}
+	if board != nil {
+		t.Errorf("expected no board, got: %+v", board)
+	}
+	if len(form.Errors["Document"]) != 1 {
+		t.Errorf("expected one Document error, got: %+v", form.Errors)
+	}
+
+	doc.Routes[0].Spaces = MaxRouteSpaces
+	form = Form{}
+	if _, err = doc.ToBoard(&form); err != nil {
+		t.Errorf("expected a route with %d spaces to be allowed, got: %+v", MaxRouteSpaces, err)
+	}
+}
+
+func TestBoardDocumentPrestigeTable(t *testing.T) {
+	assert := assert.New(t)
+	original := newPlayableTestBoard()
+	original.PrestigeTable = &PrestigeTable{
+		CityID: original.Cities[1].ID,
+		Slots: []PrestigeSlot{
+			{Order: 2, RequiredPrivilege: 2, Points: 4},
+			{Order: 1, RequiredPrivilege: 1, Points: 7},
+		},
+	}
+
+	doc := NewBoardDocument(original)
+	assert.ThatString(doc.PrestigeTable.City).IsEqualTo(doc.Cities[1].Ref)
+	assert.ThatInt(doc.PrestigeTable.Slots[0].Points).IsEqualTo(7)
+
+	form := Form{}
+	board, err := doc.ToBoard(&form)
+	if err != nil {
+		t.Fatalf("ToBoard returned error: %+v %+v", err, form.Errors)
+	}
+	assert.That(board.PrestigeTable.CityID).IsEqualTo(board.Cities[1].ID)
+	assert.ThatInt(len(board.PrestigeTable.Slots)).IsEqualTo(2)
+	assert.ThatInt(board.PrestigeTable.Slots[1].Order).IsEqualTo(2)
+
+	doc.PrestigeTable.City = "nowhere"
+	doc.PrestigeTable.Slots[0].RequiredPrivilege = 9
+	form = Form{}
+	_, err = doc.ToBoard(&form)
+	assert.That(err).IsEqualTo(ErrInvalidForm)
+	assert.ThatInt(len(form.Errors["Document"])).IsEqualTo(2)
+}
+
+func TestBoardDocumentConnectionObjective(t *testing.T) {
+	assert := assert.New(t)
+	original := newPlayableTestBoard()
+	original.ConnectionObjective = &ConnectionObjective{
+		StartCityID: original.Cities[0].ID,
+		EndCityID:   original.Cities[1].ID,
+		Awards:      []int{7, 4, 2},
+	}
+
+	doc := NewBoardDocument(original)
+	assert.ThatString(doc.ConnectionObjective.StartCity).IsEqualTo(doc.Cities[0].Ref)
+	assert.ThatString(doc.ConnectionObjective.EndCity).IsEqualTo(doc.Cities[1].Ref)
+
+	form := Form{}
+	board, err := doc.ToBoard(&form)
+	if err != nil {
+		t.Fatalf("ToBoard returned error: %+v %+v", err, form.Errors)
+	}
+	assert.That(board.ConnectionObjective.StartCityID).IsEqualTo(board.Cities[0].ID)
+	assert.That(board.ConnectionObjective.EndCityID).IsEqualTo(board.Cities[1].ID)
+	assert.ThatInt(len(board.ConnectionObjective.Awards)).IsEqualTo(3)
+
+	doc.ConnectionObjective.EndCity = "nowhere"
+	form = Form{}
+	_, err = doc.ToBoard(&form)
+	assert.That(err).IsEqualTo(ErrInvalidForm)
+	assert.ThatInt(len(form.Errors["Document"])).IsEqualTo(1)
+}
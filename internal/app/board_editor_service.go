@@ -3,38 +3,119 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
 type BoardEditorService interface {
 	FindAll(ctx context.Context) ([]Board, error)
+	// SearchBoards finds one page of the boards matching the search, in the order asked for
+	SearchBoards(ctx context.Context, form *BoardSearchForm) (*BoardPage, error)
 	FindByID(ctx context.Context, id string) (*Board, error)
+	// FindBoardGraphByID finds the board along with all of its cities, routes and spaces
+	FindBoardGraphByID(ctx context.Context, id string) (*Board, error)
+	// FindBoardForPlayerCount finds the board graph with only the cities and routes in play for the number of players
+	FindBoardForPlayerCount(ctx context.Context, id string, players string) (*Board, error)
 	CreateBoard(ctx context.Context, form *CreateBoardForm) (*Board, error)
 	Update(ctx context.Context, id string, form *UpdateBoardForm) (*Board, error)
 	UpdateName(ctx context.Context, id string, form *UpdateBoardForm) (*Board, error)
 	UpdateDimensions(ctx context.Context, id string, form *UpdateBoardForm) (*Board, error)
 	DeleteByID(ctx context.Context, id string) error
+	// PreviewDeleteByID reports what DeleteByID would remove along with the board, without deleting anything
+	PreviewDeleteByID(ctx context.Context, id string) (*BoardDeleteImpact, error)
+	ValidateBoard(ctx context.Context, id string) (*BoardValidationReport, error)
+	// FindBoardStats counts up the board's design metrics, for comparing boards for balance
+	FindBoardStats(ctx context.Context, id string) (*BoardStats, error)
+	// DiffBoards compares the board against another board or a published version, with the board as the "after" side
+	DiffBoards(ctx context.Context, id string, form *BoardDiffForm) (*BoardDiff, error)
+	ExportBoard(ctx context.Context, id string) (*BoardDocument, error)
+	ImportBoard(ctx context.Context, form *ImportBoardForm) (*Board, error)
+	DuplicateBoard(ctx context.Context, id string, form *DuplicateBoardForm) (*Board, error)
+	PublishBoard(ctx context.Context, id string) (*BoardVersion, error)
+	ListBoardVersions(ctx context.Context, boardID string) ([]BoardVersion, error)
+	FindBoardVersion(ctx context.Context, boardID string, number string) (*BoardVersion, error)
+	Undo(ctx context.Context, id string) (*Board, error)
+	Redo(ctx context.Context, id string) (*Board, error)
+	// LayoutCities moves the cities to positions computed from the routes between them
+	LayoutCities(ctx context.Context, id string, form *LayoutBoardForm) (*BoardLayout, error)
 
 	ListCitiesByBoardID(ctx context.Context, boardID string) ([]City, error)
+	FindCityByID(ctx context.Context, id string) (*City, error)
 	CreateCity(ctx context.Context, boardID string, form *CityForm) (*City, error)
 	UpdateCity(ctx context.Context, id string, form *CityForm) (*City, error)
 	DeleteCity(ctx context.Context, id string) error
+	// ApplyCityBatch makes every change in the batch to the board's cities in one transaction, or none of them
+	ApplyCityBatch(ctx context.Context, boardID string, form *CityBatchForm) (*CityBatchResult, error)
+
+	AddCitySpace(ctx context.Context, cityID string, form *AddCitySpaceForm) (*CitySpace, error)
+	UpdateCitySpace(ctx context.Context, id string, form *UpdateCitySpaceForm) (*CitySpace, error)
+	ReorderCitySpaces(ctx context.Context, cityID string, form *ReorderCitySpacesForm) ([]CitySpace, error)
+	DeleteCitySpace(ctx context.Context, id string) error
+
+	ListRoutesByBoardID(ctx context.Context, boardID string) ([]Route, error)
+	CreateRoute(ctx context.Context, boardID string, form *RouteForm) (*Route, error)
+	UpdateRoute(ctx context.Context, id string, form *RouteForm) (*Route, error)
+	DeleteRoute(ctx context.Context, id string) error
+	UpdateRouteSpaces(ctx context.Context, id string, form *RouteSpacesForm) (*Route, error)
+	// UpdateStartingTokenRoutes sets which of the board's routes get a bonus token at setup, and in what order
+	UpdateStartingTokenRoutes(ctx context.Context, boardID string, form *StartingTokenRoutesForm) ([]Route, error)
+
+	// UpdateConnectionObjective sets the board's connection objective, replacing any it already has
+	UpdateConnectionObjective(ctx context.Context, boardID string, form *ConnectionObjectiveForm) (*Board, error)
+	DeleteConnectionObjective(ctx context.Context, boardID string) (*Board, error)
+
+	FindPrestigeTable(ctx context.Context, boardID string) (*PrestigeTable, error)
+	// UpdatePrestigeTable creates the board's prestige table, or replaces it if the board already has one
+	UpdatePrestigeTable(ctx context.Context, boardID string, form *PrestigeTableForm) (*PrestigeTable, error)
+	DeletePrestigeTable(ctx context.Context, boardID string) error
 }
 
-func NewBoardEditorService(boardCrudRepository BoardCrudRepository) BoardEditorService {
+// NewBoardEditorService events may be nil if nobody needs to be told about changes; boards' images are deleted from
+// images along with the boards
+func NewBoardEditorService(boardCrudRepository BoardCrudRepository, events BoardEventPublisher, images BoardImageStore) BoardEditorService {
+	if events == nil {
+		events = noBoardEvents{}
+	}
+	if images == nil {
+		images = noBoardImages{}
+	}
 	return &boardEditorService{
-		repo: boardCrudRepository,
+		repo:   boardCrudRepository,
+		events: events,
+		images: images,
 	}
 }
 
 type boardEditorService struct {
-	repo BoardCrudRepository
+	repo   BoardCrudRepository
+	events BoardEventPublisher
+	images BoardImageStore
 }
 
 func (s boardEditorService)FindAll(ctx context.Context) ([]Board, error) {
 	return s.repo.ListBoards(ctx)
 }
 
+func (s boardEditorService)SearchBoards(ctx context.Context, form *BoardSearchForm) (*BoardPage, error) {
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	query := form.BoardQuery()
+	boards, total, err := s.repo.SearchBoards(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoardPage{
+		Boards:  boards,
+		Page:    query.Offset/query.Limit + 1,
+		PerPage: query.Limit,
+		Total:   total,
+	}, nil
+}
+
 func (s boardEditorService)FindByID(ctx context.Context, rawId string) (*Board, error) {
 	id, err := NewIDFromString(rawId)
 	if err != nil {
@@ -48,15 +129,38 @@ func (s boardEditorService)FindByID(ctx context.Context, rawId string) (*Board,
 	return board, nil
 }
 
-func (s boardEditorService)CreateBoard(ctx context.Context, form *CreateBoardForm) (*Board, error) {
-	form.Name = strings.TrimSpace(form.Name)
+func (s boardEditorService)FindBoardGraphByID(ctx context.Context, rawId string) (*Board, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(form.Name) == 0 {
-		form.AddError("Name", "must not be blank")
-	} else if len(form.Name) > 100 {
-		form.AddError("Name", "is too long; must be 100 characters or less")
+	return s.repo.GetBoardGraphByID(ctx, id)
+}
+
+func (s boardEditorService)FindBoardForPlayerCount(ctx context.Context, rawId string, rawPlayers string) (*Board, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := strconv.Atoi(rawPlayers)
+	if err != nil {
+		return nil, ErrUnsupportedPlayerCount
+	}
+
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
+	return board.ForPlayerCount(players)
+}
+
+func (s boardEditorService)CreateBoard(ctx context.Context, form *CreateBoardForm) (*Board, error) {
+	form.Name = strings.TrimSpace(form.Name)
+
+	validateBoardName(&form.Form, form.Name)
 	if form.HasError() {
 		return nil, ErrInvalidForm
 	}
@@ -92,15 +196,15 @@ func (s boardEditorService)UpdateDimensions(ctx context.Context, rawId string, f
 	if form.Height < 0 {
 		form.AddError("Height", "must be greater than or equal to zero")
 	}
+	validateResize(form)
 
 	if form.HasError() {
 		return nil, ErrInvalidForm
 	}
 
-	updatedBoard, err := s.repo.UpdateBoard(ctx, id, func (board *Board) (*Board, error) {
+	updatedBoard, err := s.updateBoard(ctx, id, form, func (board *Board) {
 		board.Width = form.Width
 		board.Height = form.Height
-		return board, nil
 	})
 	if err != nil {
 		return nil, err
@@ -127,9 +231,8 @@ func (s boardEditorService)UpdateName(ctx context.Context, rawId string, form *U
 		return nil, ErrInvalidForm
 	}
 
-	updatedBoard, err := s.repo.UpdateBoard(ctx, id, func (board *Board) (*Board, error) {
+	updatedBoard, err := s.updateBoard(ctx, id, form, func (board *Board) {
 		board.Name = form.Name
-		return board, nil
 	})
 	if err != nil {
 		if errors.Is(ErrNameTaken, err) {
@@ -162,16 +265,25 @@ func (s boardEditorService)Update(ctx context.Context, rawId string, form *Updat
 	if form.Height < 0 {
 		form.AddError("Height", "must be greater than or equal to zero")
 	}
+	validateResize(form)
+
+	if !form.PlayerRangeForm.IsEmpty() {
+		board, err := s.repo.GetBoardByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		validatePlayerRange(&form.Form, form.PlayerRangeForm.Apply(board.PlayerRange))
+	}
 
 	if form.HasError() {
 		return nil, ErrInvalidForm
 	}
 
-	updatedBoard, err := s.repo.UpdateBoard(ctx, id, func (board *Board) (*Board, error) {
+	updatedBoard, err := s.updateBoard(ctx, id, form, func (board *Board) {
 		board.Name = form.Name
 		board.Width = form.Width
 		board.Height = form.Height
-		return board, nil
+		board.PlayerRange = form.PlayerRangeForm.Apply(board.PlayerRange)
 	})
 	if err != nil {
 		if errors.Is(ErrNameTaken, err) {
@@ -184,77 +296,1499 @@ func (s boardEditorService)Update(ctx context.Context, rawId string, form *Updat
 	return updatedBoard, nil
 }
 
+// updateBoard Apply the change to the board and record it so that it can be undone.
+// If the form's ExpectedVersion is not zero, the board must still be at that version.
+// If the board is resized, its cities are moved according to the form's ResizeMode.
+func (s boardEditorService)updateBoard(ctx context.Context, id ID, form *UpdateBoardForm, change func (board *Board)) (*Board, error) {
+	var updatedBoard *Board
+	var before *Board
+	var movedCities []*City
+	err := s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		var err error
+		updatedBoard, err = repo.UpdateBoard(ctx, id, func (board *Board) (*Board, error) {
+			if form.ExpectedVersion != 0 && board.Version != form.ExpectedVersion {
+				return nil, NewStaleRecordError("Board", id)
+			}
+			before = boardFields(board)
+			change(board)
+			return board, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		after := boardFields(updatedBoard)
+		if after.Name == before.Name && after.Width == before.Width && after.Height == before.Height &&
+			after.PlayerRange == before.PlayerRange && after.ConnectionObjective.Equal(before.ConnectionObjective) {
+			return nil
+		}
+
+		var citiesBefore, citiesAfter []City
+		if after.Width != before.Width || after.Height != before.Height {
+			cities, err := repo.ListCitiesByBoardID(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			for _, original := range cities {
+				position := resizePosition(original.Position, form.ResizeMode, form.ResizeAnchor,
+					before.Width, before.Height, after.Width, after.Height)
+				if position == original.Position {
+					continue
+				}
+
+				movedCity, err := repo.UpdateCity(ctx, original.ID, func(city *City) (*City, error) {
+					city.Position = position
+					return city, nil
+				})
+				if err != nil {
+					return err
+				}
+
+				movedCities = append(movedCities, movedCity)
+				citiesBefore = append(citiesBefore, City{Model: original.Model, BoardID: id, Position: original.Position})
+				citiesAfter = append(citiesAfter, City{Model: original.Model, BoardID: id, Position: position})
+			}
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: id,
+			Kind:    CommandUpdateBoard,
+			Before:  BoardCommandState{Board: before, Cities: citiesBefore},
+			After:   BoardCommandState{Board: after, Cities: citiesAfter},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedBoard.Width != before.Width || updatedBoard.Height != before.Height {
+		s.events.Publish(BoardEvent{
+			Type:    BoardEventBoardResized,
+			BoardID: id,
+			Data:    boardFields(updatedBoard),
+		})
+	}
+	for _, city := range movedCities {
+		s.events.Publish(BoardEvent{
+			Type:    BoardEventCityUpdated,
+			BoardID: id,
+			Data:    city,
+		})
+	}
+
+	return updatedBoard, nil
+}
+
 func (s boardEditorService)DeleteByID(ctx context.Context, rawId string) error {
 	id, err := NewIDFromString(rawId)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.DeleteBoardByID(ctx, id)
+	if err = s.repo.DeleteBoardByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.images.DeleteBoardImage(ctx, id)
 }
 
-func (s boardEditorService)ListCitiesByBoardID(ctx context.Context, boardID string) ([]City, error) {
-	id, err := NewIDFromString(boardID)
+func (s boardEditorService)PreviewDeleteByID(ctx context.Context, rawId string) (*BoardDeleteImpact, error) {
+	id, err := NewIDFromString(rawId)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.ListCitiesByBoardID(ctx, id)
+	return s.repo.GetBoardDeleteImpact(ctx, id)
 }
 
-func (s boardEditorService)CreateCity(ctx context.Context, boardID string, form *CityForm) (*City, error) {
-	parsedBoardID, err := NewIDFromString(boardID)
+func (s boardEditorService)ValidateBoard(ctx context.Context, rawId string) (*BoardValidationReport, error) {
+	id, err := NewIDFromString(rawId)
 	if err != nil {
 		return nil, err
 	}
 
-	form.NormalizeInputs()
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := NewBoardValidator().Validate(board)
+	return &report, nil
+}
+
+func (s boardEditorService)FindBoardStats(ctx context.Context, rawId string) (*BoardStats, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := NewBoardStats(board)
+	return &stats, nil
+}
+
+func (s boardEditorService)DiffBoards(ctx context.Context, rawId string, form *BoardDiffForm) (*BoardDiff, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
 
 	if !form.IsValid() {
 		return nil, ErrInvalidForm
 	}
 
-	city := City{
-		Model:      Model{},
-		BoardID:    parsedBoardID,
-		Name:       form.Name,
-		Position:   form.Position,
-		CitySpaces: nil,
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	after := NewBoardDocument(board)
+
+	againstID := id
+	if form.Against != "" {
+		if againstID, err = NewIDFromString(form.Against); err != nil {
+			return nil, err
+		}
 	}
 
-	err = s.repo.CreateCity(ctx, &city)
-	return &city, err
+	var before BoardDocument
+	var beforeLabel string
+	if form.Version != "" {
+		number, err := strconv.Atoi(form.Version)
+		if err != nil {
+			return nil, err
+		}
+		version, err := s.repo.GetBoardVersionByNumber(ctx, againstID, number)
+		if err != nil {
+			return nil, err
+		}
+		before = version.Snapshot
+		beforeLabel = fmt.Sprintf("%s (version %d)", before.Board.Name, version.Number)
+	} else {
+		against, err := s.repo.GetBoardGraphByID(ctx, againstID)
+		if err != nil {
+			return nil, err
+		}
+		before = NewBoardDocument(against)
+		beforeLabel = before.Board.Name
+	}
+
+	diff := NewBoardDiff(&before, &after)
+	diff.Before = beforeLabel
+	return &diff, nil
 }
 
-func (s boardEditorService)UpdateCity(ctx context.Context, id string, form *CityForm) (*City, error) {
-	parsedID, err := NewIDFromString(id)
+func (s boardEditorService)ExportBoard(ctx context.Context, rawId string) (*BoardDocument, error) {
+	id, err := NewIDFromString(rawId)
 	if err != nil {
 		return nil, err
 	}
 
-	form.NormalizeInputs()
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	if !form.IsValid() {
+	doc := NewBoardDocument(board)
+	return &doc, nil
+}
+
+func (s boardEditorService)ImportBoard(ctx context.Context, form *ImportBoardForm) (*Board, error) {
+	form.Name = strings.TrimSpace(form.Name)
+	if form.Name == "" {
+		form.Name = strings.TrimSpace(form.Document.Board.Name)
+	}
+
+	validateBoardName(&form.Form, form.Name)
+	if form.Document.Board.Width < 0 {
+		form.AddError("Document", "board width must be greater than or equal to zero")
+	}
+	if form.Document.Board.Height < 0 {
+		form.AddError("Document", "board height must be greater than or equal to zero")
+	}
+
+	board, err := form.Document.ToBoard(&form.Form)
+	if err != nil {
+		return nil, err
+	}
+	if form.HasError() {
+		return nil, ErrInvalidForm
+	}
+
+	board.Name = form.Name
+
+	if err = s.repo.CreateBoardGraph(ctx, board); err != nil {
+		if errors.Is(ErrNameTaken, err) {
+			form.AddError("Name", "is already taken")
+			return nil, ErrInvalidForm
+		}
+		return nil, err
+	}
+
+	return board, nil
+}
+
+// DuplicateBoard Copy the board with the given ID, along with all of its cities, routes and spaces, under a new name
+func (s boardEditorService)DuplicateBoard(ctx context.Context, rawId string, form *DuplicateBoardForm) (*Board, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	validateBoardName(&form.Form, form.Name)
+	if form.HasError() {
 		return nil, ErrInvalidForm
 	}
 
-	updatedCity, err := s.repo.UpdateCity(ctx, parsedID, func(city *City) (*City, error) {
-		city.Name = form.Name
-		city.Position.X	= form.Position.X
-		city.Position.Y = form.Position.Y
-		return city, nil
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	board.Name = form.Name
+
+	if err = s.repo.CreateBoardGraph(ctx, board); err != nil {
+		if errors.Is(ErrNameTaken, err) {
+			form.AddError("Name", "is already taken")
+			return nil, ErrInvalidForm
+		}
+		return nil, err
+	}
+
+	return board, nil
+}
+
+// PublishBoard Freeze the board as it is now into a new numbered version that games can be played on
+func (s boardEditorService)PublishBoard(ctx context.Context, rawId string) (*BoardVersion, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	version := BoardVersion{
+		BoardID:  board.ID,
+		Snapshot: NewBoardDocument(board),
+	}
+	if err = s.repo.CreateBoardVersion(ctx, &version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+func (s boardEditorService)ListBoardVersions(ctx context.Context, boardID string) ([]BoardVersion, error) {
+	id, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListBoardVersionsByBoardID(ctx, id)
+}
+
+func (s boardEditorService)FindBoardVersion(ctx context.Context, boardID string, rawNumber string) (*BoardVersion, error) {
+	id, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+	number, err := strconv.Atoi(rawNumber)
+	if err != nil || number < 1 {
+		return nil, &ErrInvalidIDString{
+			Msg:   fmt.Sprintf("invalid version number: %q", rawNumber),
+			Cause: err,
+		}
+	}
+
+	return s.repo.GetBoardVersionByNumber(ctx, id, number)
+}
+
+// Undo Reverse the most recent change to the board that hasn't already been undone
+func (s boardEditorService)Undo(ctx context.Context, rawId string) (*Board, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		if _, err := repo.GetBoardByID(ctx, id); err != nil {
+			return err
+		}
+
+		command, err := repo.GetLastBoardCommand(ctx, id)
+		if err != nil {
+			if errors.Is(RecordNotFound{}, err) {
+				return ErrNothingToUndo
+			}
+			return err
+		}
+
+		if err = command.undo(ctx, repo); err != nil {
+			return err
+		}
+		return repo.SetBoardCommandUndone(ctx, command.ID, true)
 	})
 	if err != nil {
 		return nil, err
 	}
-	return updatedCity, nil
+
+	return s.publishRestoredBoard(ctx, id)
 }
 
-func (s boardEditorService)DeleteCity(ctx context.Context, id string) error {
-	parsedID, err := NewIDFromString(id)
+// Redo Make the most recently undone change to the board again
+func (s boardEditorService)Redo(ctx context.Context, rawId string) (*Board, error) {
+	id, err := NewIDFromString(rawId)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		if _, err := repo.GetBoardByID(ctx, id); err != nil {
+			return err
+		}
+
+		command, err := repo.GetLastUndoneBoardCommand(ctx, id)
+		if err != nil {
+			if errors.Is(RecordNotFound{}, err) {
+				return ErrNothingToRedo
+			}
+			return err
+		}
+
+		if err = command.redo(ctx, repo); err != nil {
+			return err
+		}
+		return repo.SetBoardCommandUndone(ctx, command.ID, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.publishRestoredBoard(ctx, id)
+}
+
+// publishRestoredBoard Load the whole board after an undo or redo and tell everyone looking at it
+func (s boardEditorService)publishRestoredBoard(ctx context.Context, id ID) (*Board, error) {
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(BoardEvent{
+		Type:    BoardEventBoardRestored,
+		BoardID: id,
+		Data:    board,
+	})
+
+	return board, nil
+}
+
+func (s boardEditorService)LayoutCities(ctx context.Context, rawId string, form *LayoutBoardForm) (*BoardLayout, error) {
+	id, err := NewIDFromString(rawId)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := s.repo.GetBoardGraphByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.Iterations == 0 {
+		form.Iterations = DefaultLayoutIterations
+	}
+	if form.Iterations < 0 || form.Iterations > MaxLayoutIterations {
+		form.AddError("Iterations", fmt.Sprintf("must be between 1 and %d", MaxLayoutIterations))
+	}
+	if board.Width <= 0 || board.Height <= 0 {
+		form.AddError("Board", "must have a width and height to lay out cities on")
+	}
+	if form.HasError() {
+		return nil, ErrInvalidForm
+	}
+
+	positions := layoutCities(board.Cities, board.Routes, board.Width, board.Height, form.Iterations)
+
+	layout := BoardLayout{
+		BoardID: id,
+		DryRun:  form.DryRun,
+		Cities:  make([]City, len(board.Cities)),
+	}
+	for i, city := range board.Cities {
+		city.Position = positions[city.ID]
+		layout.Cities[i] = city
+	}
+
+	if form.DryRun {
+		return &layout, nil
+	}
+
+	var moved []*City
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		var before, after []City
+		for i := range layout.Cities {
+			original := board.Cities[i]
+			if original.Position == layout.Cities[i].Position {
+				continue
+			}
+
+			updatedCity, err := repo.UpdateCity(ctx, original.ID, func(city *City) (*City, error) {
+				city.Position = positions[city.ID]
+				return city, nil
+			})
+			if err != nil {
+				return err
+			}
+
+			updatedCity.CitySpaces = original.CitySpaces
+			layout.Cities[i] = *updatedCity
+			moved = append(moved, updatedCity)
+			before = append(before, City{Model: original.Model, BoardID: id, Position: original.Position})
+			after = append(after, City{Model: original.Model, BoardID: id, Position: updatedCity.Position})
+		}
+
+		if len(moved) == 0 {
+			return nil
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: id,
+			Kind:    CommandLayoutCities,
+			Before:  BoardCommandState{Cities: before},
+			After:   BoardCommandState{Cities: after},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, city := range moved {
+		s.events.Publish(BoardEvent{
+			Type:    BoardEventCityUpdated,
+			BoardID: id,
+			Data:    city,
+		})
+	}
+
+	return &layout, nil
+}
+
+// validateBoardName applies the same rules to every board name, whether the board is new, renamed, imported, or duplicated
+func validateBoardName(form *Form, name string) {
+	if len(name) == 0 {
+		form.AddError("Name", "must not be blank")
+	} else if len(name) > 100 {
+		form.AddError("Name", "is too long; must be 100 characters or less")
+	}
+}
+
+func (s boardEditorService)ListCitiesByBoardID(ctx context.Context, boardID string) ([]City, error) {
+	id, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListCitiesByBoardID(ctx, id)
+}
+
+func (s boardEditorService)FindCityByID(ctx context.Context, id string) (*City, error) {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetCityByID(ctx, parsedID)
+}
+
+func (s boardEditorService)CreateCity(ctx context.Context, boardID string, form *CityForm) (*City, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	form.NormalizeInputs()
+
+	playerRange := form.PlayerRangeForm.Apply(PlayerRange{})
+	validatePlayerRange(&form.Form, playerRange)
+	upgrade := form.ApplyUpgrade(AbilityNone)
+	validateAbility(&form.Form, "Upgrade", upgrade)
+
+	if !form.IsValid() || form.HasError() {
+		return nil, ErrInvalidForm
+	}
+
+	city := City{
+		Model:       Model{},
+		BoardID:     parsedBoardID,
+		Name:        form.Name,
+		Position:    form.Position,
+		PlayerRange: playerRange,
+		Upgrade:     upgrade,
+		CitySpaces:  nil,
+	}
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		if err := repo.CreateCity(ctx, &city); err != nil {
+			return err
+		}
+
+		if form.Warnings, err = cityWarnings(ctx, repo, &city); err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: city.BoardID,
+			Kind:    CommandCreateCity,
+			After:   BoardCommandState{City: cityFields(&city)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(BoardEvent{
+		Type:    BoardEventCityCreated,
+		BoardID: city.BoardID,
+		Data:    &city,
+	})
+
+	return &city, nil
+}
+
+func (s boardEditorService)UpdateCity(ctx context.Context, id string, form *CityForm) (*City, error) {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	form.NormalizeInputs()
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	var updatedCity *City
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		var before *City
+		updatedCity, err = repo.UpdateCity(ctx, parsedID, func(city *City) (*City, error) {
+			if form.ExpectedVersion != 0 && city.Version != form.ExpectedVersion {
+				return nil, NewStaleRecordError("City", parsedID)
+			}
+			before = cityFields(city)
+			city.Name = form.Name
+			city.Position.X	= form.Position.X
+			city.Position.Y = form.Position.Y
+			city.PlayerRange = form.PlayerRangeForm.Apply(city.PlayerRange)
+			city.Upgrade = form.ApplyUpgrade(city.Upgrade)
+
+			validatePlayerRange(&form.Form, city.PlayerRange)
+			validateAbility(&form.Form, "Upgrade", city.Upgrade)
+			if form.HasError() {
+				return nil, ErrInvalidForm
+			}
+			return city, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if form.Warnings, err = cityWarnings(ctx, repo, updatedCity); err != nil {
+			return err
+		}
+
+		if updatedCity.Name == before.Name && updatedCity.Position == before.Position && updatedCity.PlayerRange == before.PlayerRange &&
+			updatedCity.Upgrade == before.Upgrade {
+			return nil
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: updatedCity.BoardID,
+			Kind:    CommandUpdateCity,
+			Before:  BoardCommandState{City: before},
+			After:   BoardCommandState{City: cityFields(updatedCity)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(BoardEvent{
+		Type:    BoardEventCityUpdated,
+		BoardID: updatedCity.BoardID,
+		Data:    updatedCity,
+	})
+
+	return updatedCity, nil
+}
+
+// cityWarnings Check where the saved city is on its board. The city's spaces are loaded along with the
+// other cities, since they decide its size.
+func cityWarnings(ctx context.Context, repo BoardCrudRepository, city *City) ([]CityWarning, error) {
+	board, err := repo.GetBoardByID(ctx, city.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	cities, err := repo.ListCitiesByBoardID(ctx, city.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := *city
+	for _, other := range cities {
+		if other.ID == city.ID {
+			saved.CitySpaces = other.CitySpaces
+		}
+	}
+
+	return checkCityGeometry(&saved, board, cities), nil
+}
+
+func (s boardEditorService)DeleteCity(ctx context.Context, id string) error {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return err
+	}
+
+	var boardID ID
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		city, err := repo.GetCityByID(ctx, parsedID)
+		if err != nil {
+			return err
+		}
+		boardID = city.BoardID
+
+		routes, err := repo.ListRoutesByBoardID(ctx, city.BoardID)
+		if err != nil {
+			return err
+		}
+		var cityRoutes []Route
+		for _, route := range routes {
+			if route.StartCityID == city.ID || route.EndCityID == city.ID {
+				cityRoutes = append(cityRoutes, route)
+			}
+		}
+
+		before, after, err := deletedCityState(ctx, repo, city, cityRoutes)
+		if err != nil {
+			return err
+		}
+
+		if err = repo.DeleteCityByID(ctx, parsedID); err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: city.BoardID,
+			Kind:    CommandDeleteCity,
+			Before:  before,
+			After:   after,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(BoardEvent{
+		Type:    BoardEventCityDeleted,
+		BoardID: boardID,
+		Data:    DeletedCity{ID: parsedID, BoardID: boardID},
+	})
+
+	return nil
+}
+
+// deletedCityState The states to record before and after deleting the city. Before holds the city itself, the given
+// routes leading out of it and the prestige table it hosts, which are all deleted along with it. When the city is
+// part of the board's connection objective, which is cleared along with it, both hold the board's fields.
+func deletedCityState(ctx context.Context, repo BoardCrudRepository, city *City, routes []Route) (before, after BoardCommandState, err error) {
+	before = BoardCommandState{City: city, Routes: routes}
+
+	table, err := repo.GetPrestigeTableByBoardID(ctx, city.BoardID)
+	if err != nil && !errors.Is(RecordNotFound{}, err) {
+		return before, after, err
+	}
+	if table != nil && table.CityID == city.ID {
+		before.PrestigeTable = table
+	}
+
+	board, err := repo.GetBoardByID(ctx, city.BoardID)
+	if err != nil {
+		return before, after, err
+	}
+	if objective := board.ConnectionObjective; objective != nil && (objective.StartCityID == city.ID || objective.EndCityID == city.ID) {
+		before.Board = boardFields(board)
+		after.Board = boardFields(board)
+		after.Board.ConnectionObjective = nil
+	}
+
+	return before, after, nil
+}
+
+func (s boardEditorService)ApplyCityBatch(ctx context.Context, boardID string, form *CityBatchForm) (*CityBatchResult, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	result := CityBatchResult{
+		IDs:     make(map[int64]ID),
+		Cities:  []City{},
+		Deleted: []ID{},
+	}
+	var events []BoardEvent
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		if _, err := repo.GetBoardByID(ctx, parsedBoardID); err != nil {
+			return err
+		}
+
+		routes, err := repo.ListRoutesByBoardID(ctx, parsedBoardID)
+		if err != nil {
+			return err
+		}
+
+		// getCity Find a city on this board by the ID the client knows it by
+		getCity := func(clientID int64) (*City, error) {
+			id := ID(clientID)
+			if clientID < 0 {
+				id = result.IDs[clientID]
+			}
+			city, err := repo.GetCityByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if city.BoardID != parsedBoardID {
+				return nil, NewRecordNotFoundError("City", id)
+			}
+			return city, nil
+		}
+
+		var before, after []BoardCommandState
+		for i, operation := range form.Operations {
+			switch operation.Op {
+			case CityBatchCreate:
+				city := City{
+					BoardID:     parsedBoardID,
+					Name:        operation.City.Name,
+					Position:    operation.City.Position,
+					PlayerRange: operation.City.PlayerRangeForm.Apply(PlayerRange{}),
+					Upgrade:     operation.City.ApplyUpgrade(AbilityNone),
+				}
+				if err := repo.CreateCity(ctx, &city); err != nil {
+					return err
+				}
+				result.IDs[operation.ID] = city.ID
+
+				before = append(before, BoardCommandState{})
+				after = append(after, BoardCommandState{City: cityFields(&city)})
+				events = append(events, BoardEvent{Type: BoardEventCityCreated, BoardID: parsedBoardID, Data: &city})
+
+			case CityBatchUpdate:
+				city, err := getCity(operation.ID)
+				if err != nil {
+					return err
+				}
+				if operation.Version != 0 && city.Version != operation.Version {
+					return NewStaleRecordError("City", city.ID)
+				}
+
+				playerRange := operation.City.PlayerRangeForm.Apply(city.PlayerRange)
+				validatePlayerRange(&operation.City.Form, playerRange)
+				if operation.City.HasError() {
+					form.AddError(fmt.Sprintf("Operations[%d]", i), "city has an invalid player range")
+					return ErrInvalidForm
+				}
+				upgrade := operation.City.ApplyUpgrade(city.Upgrade)
+
+				updatedCity, err := repo.UpdateCity(ctx, city.ID, func(city *City) (*City, error) {
+					city.Name = operation.City.Name
+					city.Position = operation.City.Position
+					city.PlayerRange = playerRange
+					city.Upgrade = upgrade
+					return city, nil
+				})
+				if err != nil {
+					return err
+				}
+
+				before = append(before, BoardCommandState{City: cityFields(city)})
+				after = append(after, BoardCommandState{City: cityFields(updatedCity)})
+				events = append(events, BoardEvent{Type: BoardEventCityUpdated, BoardID: parsedBoardID, Data: updatedCity})
+
+			case CityBatchDelete:
+				city, err := getCity(operation.ID)
+				if err != nil {
+					return err
+				}
+
+				// A route between two deleted cities is put back with whichever is put back last on undo,
+				// which is the one deleted first
+				var cityRoutes, remainingRoutes []Route
+				for _, route := range routes {
+					if route.StartCityID == city.ID || route.EndCityID == city.ID {
+						cityRoutes = append(cityRoutes, route)
+					} else {
+						remainingRoutes = append(remainingRoutes, route)
+					}
+				}
+				routes = remainingRoutes
+
+				deletedBefore, deletedAfter, err := deletedCityState(ctx, repo, city, cityRoutes)
+				if err != nil {
+					return err
+				}
+
+				if err = repo.DeleteCityByID(ctx, city.ID); err != nil {
+					return err
+				}
+				result.Deleted = append(result.Deleted, city.ID)
+
+				before = append(before, deletedBefore)
+				after = append(after, deletedAfter)
+				events = append(events, BoardEvent{Type: BoardEventCityDeleted, BoardID: parsedBoardID, Data: DeletedCity{ID: city.ID, BoardID: parsedBoardID}})
+			}
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: parsedBoardID,
+			Kind:    CommandCityBatch,
+			Before:  BoardCommandState{Steps: before},
+			After:   BoardCommandState{Steps: after},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the last state of each city that is still on the board is returned
+	latest := make(map[ID]int)
+	for _, event := range events {
+		switch data := event.Data.(type) {
+		case *City:
+			if i, found := latest[data.ID]; found {
+				result.Cities[i] = *data
+			} else {
+				latest[data.ID] = len(result.Cities)
+				result.Cities = append(result.Cities, *data)
+			}
+		}
+	}
+	cities := result.Cities[:0]
+	for _, city := range result.Cities {
+		if !containsID(result.Deleted, city.ID) {
+			cities = append(cities, city)
+		}
+	}
+	result.Cities = cities
+
+	for _, event := range events {
+		s.events.Publish(event)
+	}
+
+	return &result, nil
+}
+
+func containsID(ids []ID, id ID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s boardEditorService)AddCitySpace(ctx context.Context, cityID string, form *AddCitySpaceForm) (*CitySpace, error) {
+	parsedCityID, err := NewIDFromString(cityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.GetCityByID(ctx, parsedCityID); err != nil {
+		return nil, err
+	}
+
+	form.CityID = parsedCityID
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	space := CitySpace{
+		CityID:            parsedCityID,
+		SpaceType:         form.SpaceType,
+		RequiredPrivilege: form.RequiredPrivilege,
+	}
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		return recordCitySpaces(ctx, repo, parsedCityID, CommandAddCitySpace, func(existingSpaces []CitySpace) error {
+			space.Order = 1
+			for _, existing := range existingSpaces {
+				if existing.Order >= space.Order {
+					space.Order = existing.Order + 1
+				}
+			}
+
+			return repo.CreateCitySpace(ctx, &space)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &space, nil
+}
+
+func (s boardEditorService)UpdateCitySpace(ctx context.Context, id string, form *UpdateCitySpaceForm) (*CitySpace, error) {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	var updatedSpace *CitySpace
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		space, err := repo.GetCitySpaceByID(ctx, parsedID)
+		if err != nil {
+			return err
+		}
+
+		return recordCitySpaces(ctx, repo, space.CityID, CommandUpdateCitySpace, func([]CitySpace) error {
+			updatedSpace, err = repo.UpdateCitySpace(ctx, parsedID, func(space *CitySpace) (*CitySpace, error) {
+				space.SpaceType = form.SpaceType
+				space.RequiredPrivilege = form.RequiredPrivilege
+				return space, nil
+			})
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSpace, nil
+}
+
+func (s boardEditorService)ReorderCitySpaces(ctx context.Context, cityID string, form *ReorderCitySpacesForm) ([]CitySpace, error) {
+	parsedCityID, err := NewIDFromString(cityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.GetCityByID(ctx, parsedCityID); err != nil {
+		return nil, err
+	}
+
+	var reordered []CitySpace
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		return recordCitySpaces(ctx, repo, parsedCityID, CommandReorderCitySpaces, func(spaces []CitySpace) error {
+			if !form.IsValidFor(spaces) {
+				return ErrInvalidForm
+			}
+
+			reordered, err = repo.ReorderCitySpaces(ctx, parsedCityID, form.SpaceIDs)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reordered, nil
+}
+
+func (s boardEditorService)DeleteCitySpace(ctx context.Context, id string) error {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		space, err := repo.GetCitySpaceByID(ctx, parsedID)
+		if err != nil {
+			return err
+		}
+
+		return recordCitySpaces(ctx, repo, space.CityID, CommandDeleteCitySpace, func([]CitySpace) error {
+			return repo.DeleteCitySpaceByID(ctx, parsedID)
+		})
+	})
+}
+
+// recordCitySpaces Make a change to the spaces of a city, given the spaces it has beforehand, and record all of
+// the city's spaces before and after the change as a command
+func recordCitySpaces(ctx context.Context, repo BoardCrudRepository, cityID ID, kind BoardCommandKind, change func(before []CitySpace) error) error {
+	city, err := repo.GetCityByID(ctx, cityID)
+	if err != nil {
+		return err
+	}
+
+	before, err := repo.GetCitySpacesByCityID(ctx, cityID)
+	if err != nil {
+		return err
+	}
+
+	if err = change(before); err != nil {
+		return err
+	}
+
+	after, err := repo.GetCitySpacesByCityID(ctx, cityID)
+	if err != nil {
+		return err
+	}
+
+	return repo.CreateBoardCommand(ctx, &BoardCommand{
+		BoardID: city.BoardID,
+		Kind:    kind,
+		Before:  BoardCommandState{CitySpaces: &CitySpacesState{CityID: cityID, Spaces: before}},
+		After:   BoardCommandState{CitySpaces: &CitySpacesState{CityID: cityID, Spaces: after}},
+	})
+}
+
+func (s boardEditorService)ListRoutesByBoardID(ctx context.Context, boardID string) ([]Route, error) {
+	id, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListRoutesByBoardID(ctx, id)
+}
+
+func (s boardEditorService)CreateRoute(ctx context.Context, boardID string, form *RouteForm) (*Route, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.GetBoardByID(ctx, parsedBoardID); err != nil {
+		return nil, err
+	}
+
+	validatePlayerRange(&form.Form, form.PlayerRangeForm.Apply(PlayerRange{}))
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	if err = s.validateRouteCities(ctx, parsedBoardID, form); err != nil {
+		return nil, err
+	}
+
+	route := Route{
+		BoardID:     parsedBoardID,
+		StartCityID: form.StartCityID,
+		EndCityID:   form.EndCityID,
+		PlayerRange: form.PlayerRangeForm.Apply(PlayerRange{}),
+	}
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		if err := repo.CreateRoute(ctx, &route); err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: route.BoardID,
+			Kind:    CommandCreateRoute,
+			After:   BoardCommandState{Route: routeFields(&route)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &route, nil
+}
+
+func (s boardEditorService)UpdateRoute(ctx context.Context, id string, form *RouteForm) (*Route, error) {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	route, err := s.repo.GetRouteByID(ctx, parsedID)
+	if err != nil {
+		return nil, err
+	}
+
+	validatePlayerRange(&form.Form, form.PlayerRangeForm.Apply(route.PlayerRange))
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	if err = s.validateRouteCities(ctx, route.BoardID, form); err != nil {
+		return nil, err
+	}
+
+	var updatedRoute *Route
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		var before *Route
+		updatedRoute, err = repo.UpdateRoute(ctx, parsedID, func(route *Route) (*Route, error) {
+			before = routeFields(route)
+			route.StartCityID = form.StartCityID
+			route.EndCityID = form.EndCityID
+			route.PlayerRange = form.PlayerRangeForm.Apply(route.PlayerRange)
+			return route, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: updatedRoute.BoardID,
+			Kind:    CommandUpdateRoute,
+			Before:  BoardCommandState{Route: before},
+			After:   BoardCommandState{Route: routeFields(updatedRoute)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedRoute, nil
+}
+
+func (s boardEditorService)DeleteRoute(ctx context.Context, id string) error {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		route, err := repo.GetRouteByID(ctx, parsedID)
+		if err != nil {
+			return err
+		}
+
+		if err = repo.DeleteRouteByID(ctx, parsedID); err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: route.BoardID,
+			Kind:    CommandDeleteRoute,
+			Before:  BoardCommandState{Route: route},
+		})
+	})
+}
+
+func (s boardEditorService)UpdateRouteSpaces(ctx context.Context, id string, form *RouteSpacesForm) (*Route, error) {
+	parsedID, err := NewIDFromString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedRoute *Route
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		var before *Route
+		updatedRoute, err = repo.UpdateRouteSpaces(ctx, parsedID, func(route *Route) (*Route, error) {
+			if !form.IsValidFor(route) {
+				return nil, ErrInvalidForm
+			}
+			before = routeFields(route)
+
+			spaces := make([]RouteSpace, 0, len(form.Spaces))
+			for i, space := range form.Spaces {
+				spaces = append(spaces, RouteSpace{
+					Model:   Model{ID: space.ID},
+					RouteID: route.ID,
+					Order:   i + 1,
+				})
+			}
+
+			route.TavernFlag = form.TavernFlag
+			if !route.TavernFlag {
+				// Without a tavern there's nowhere to put a starting token
+				route.StartingTokenOrder = 0
+			}
+			route.RouteSpaces = spaces
+			return route, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: updatedRoute.BoardID,
+			Kind:    CommandUpdateRouteSpaces,
+			Before:  BoardCommandState{Route: before},
+			After:   BoardCommandState{Route: routeFields(updatedRoute)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedRoute, nil
+}
+
+func (s boardEditorService)UpdateConnectionObjective(ctx context.Context, boardID string, form *ConnectionObjectiveForm) (*Board, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.GetBoardByID(ctx, parsedBoardID); err != nil {
+		return nil, err
+	}
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	ends := []struct {
+		field  string
+		cityID ID
+	}{
+		{"StartCityID", form.StartCityID},
+		{"EndCityID", form.EndCityID},
+	}
+	for _, end := range ends {
+		city, err := s.repo.GetCityByID(ctx, end.cityID)
+		if err != nil {
+			if !errors.Is(RecordNotFound{}, err) {
+				return nil, err
+			}
+			form.AddError(end.field, "does not exist")
+			continue
+		}
+		if city.BoardID != parsedBoardID {
+			form.AddError(end.field, "must be a city on the same board")
+		}
+	}
+	if form.HasError() {
+		return nil, ErrInvalidForm
+	}
+
+	return s.updateBoard(ctx, parsedBoardID, &UpdateBoardForm{}, func(board *Board) {
+		board.ConnectionObjective = form.ConnectionObjective()
+	})
+}
+
+func (s boardEditorService)DeleteConnectionObjective(ctx context.Context, boardID string) (*Board, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateBoard(ctx, parsedBoardID, &UpdateBoardForm{}, func(board *Board) {
+		board.ConnectionObjective = nil
+	})
+}
+
+func (s boardEditorService)FindPrestigeTable(ctx context.Context, boardID string) (*PrestigeTable, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPrestigeTableByBoardID(ctx, parsedBoardID)
+}
+
+func (s boardEditorService)UpdatePrestigeTable(ctx context.Context, boardID string, form *PrestigeTableForm) (*PrestigeTable, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.GetBoardByID(ctx, parsedBoardID); err != nil {
+		return nil, err
+	}
+
+	if !form.IsValid() {
+		return nil, ErrInvalidForm
+	}
+
+	city, err := s.repo.GetCityByID(ctx, form.CityID)
+	if err != nil {
+		if !errors.Is(RecordNotFound{}, err) {
+			return nil, err
+		}
+		form.AddError("CityID", "does not exist")
+		return nil, ErrInvalidForm
+	}
+	if city.BoardID != parsedBoardID {
+		form.AddError("CityID", "must be a city on the same board")
+		return nil, ErrInvalidForm
+	}
+
+	table := form.PrestigeTable(parsedBoardID)
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		before, err := repo.GetPrestigeTableByBoardID(ctx, parsedBoardID)
+		if err != nil && !errors.Is(RecordNotFound{}, err) {
+			return err
+		}
+
+		if err = repo.SavePrestigeTable(ctx, table); err != nil {
+			return err
+		}
+		if err = touchBoard(ctx, repo, parsedBoardID); err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: parsedBoardID,
+			Kind:    CommandUpdatePrestigeTable,
+			Before:  BoardCommandState{PrestigeTableChange: &PrestigeTableState{Table: before}},
+			After:   BoardCommandState{PrestigeTableChange: &PrestigeTableState{Table: prestigeTableFields(table)}},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.events.Publish(BoardEvent{
+		Type:    BoardEventPrestigeTableUpdated,
+		BoardID: parsedBoardID,
+		Data:    table,
+	})
+
+	return table, nil
+}
+
+func (s boardEditorService)DeletePrestigeTable(ctx context.Context, boardID string) error {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		before, err := repo.GetPrestigeTableByBoardID(ctx, parsedBoardID)
+		if err != nil {
+			return err
+		}
+
+		if err = repo.DeletePrestigeTableByBoardID(ctx, parsedBoardID); err != nil {
+			return err
+		}
+		if err = touchBoard(ctx, repo, parsedBoardID); err != nil {
+			return err
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: parsedBoardID,
+			Kind:    CommandDeletePrestigeTable,
+			Before:  BoardCommandState{PrestigeTableChange: &PrestigeTableState{Table: before}},
+			After:   BoardCommandState{PrestigeTableChange: &PrestigeTableState{}},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(BoardEvent{
+		Type:    BoardEventPrestigeTableUpdated,
+		BoardID: parsedBoardID,
+		Data:    nil,
+	})
+
+	return nil
+}
+
+func (s boardEditorService)UpdateStartingTokenRoutes(ctx context.Context, boardID string, form *StartingTokenRoutesForm) ([]Route, error) {
+	parsedBoardID, err := NewIDFromString(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.GetBoardByID(ctx, parsedBoardID); err != nil {
+		return nil, err
+	}
+
+	routes, err := s.repo.ListRoutesByBoardID(ctx, parsedBoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !form.IsValidFor(routes) {
+		return nil, ErrInvalidForm
+	}
+
+	updatedRoutes := make([]Route, 0, len(routes))
+	err = s.repo.Transaction(ctx, func(repo BoardCrudRepository) error {
+		var before, after []BoardCommandState
+		for _, route := range routes {
+			order := form.StartingTokenOrder(route.ID)
+			if order == route.StartingTokenOrder {
+				updatedRoutes = append(updatedRoutes, route)
+				continue
+			}
+
+			var beforeRoute *Route
+			updatedRoute, err := repo.UpdateRoute(ctx, route.ID, func(route *Route) (*Route, error) {
+				beforeRoute = routeFields(route)
+				route.StartingTokenOrder = order
+				return route, nil
+			})
+			if err != nil {
+				return err
+			}
+			updatedRoutes = append(updatedRoutes, *updatedRoute)
+
+			before = append(before, BoardCommandState{Route: beforeRoute})
+			after = append(after, BoardCommandState{Route: routeFields(updatedRoute)})
+		}
+
+		if len(before) == 0 {
+			return nil
+		}
+
+		return repo.CreateBoardCommand(ctx, &BoardCommand{
+			BoardID: parsedBoardID,
+			Kind:    CommandStartingTokens,
+			Before:  BoardCommandState{Steps: before},
+			After:   BoardCommandState{Steps: after},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedRoutes, nil
+}
+
+// validateRouteCities adds form errors if either end of the route does not exist or is on another board.
+func (s boardEditorService)validateRouteCities(ctx context.Context, boardID ID, form *RouteForm) error {
+	ends := []struct {
+		field  string
+		cityID ID
+	}{
+		{"StartCityID", form.StartCityID},
+		{"EndCityID", form.EndCityID},
+	}
+
+	for _, end := range ends {
+		city, err := s.repo.GetCityByID(ctx, end.cityID)
+		if err != nil {
+			if errors.Is(RecordNotFound{}, err) {
+				form.AddError(end.field, "does not exist")
+				continue
+			}
+			return err
+		}
+
+		if city.BoardID != boardID {
+			form.AddError(end.field, "must be a city on the same board")
+		}
+	}
+
+	if form.HasError() {
+		return ErrInvalidForm
 	}
 
-	return s.repo.DeleteCityByID(ctx, parsedID)
+	return nil
 }
@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"github.com/assertgo/assert"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestFindAll(t *testing.T) {
 	repo := fakeBoardCrudRepository{}
-	service := NewBoardEditorService(&repo)
+	service := NewBoardEditorService(&repo, nil, nil)
 	ctx := context.Background()
 
 	results, err := service.FindAll(ctx)
@@ -75,7 +76,7 @@ func TestFindAll(t *testing.T) {
 
 func TestFindByID(t *testing.T) {
 	repo := fakeBoardCrudRepository{}
-	service := NewBoardEditorService(&repo)
+	service := NewBoardEditorService(&repo, nil, nil)
 	ctx := context.Background()
 
 	_, err := service.FindByID(ctx, "1")
@@ -162,7 +163,7 @@ func TestFindByID(t *testing.T) {
 
 func TestCreateBoard(t *testing.T) {
 	repo := fakeBoardCrudRepository{}
-	service := NewBoardEditorService(&repo)
+	service := NewBoardEditorService(&repo, nil, nil)
 	ctx := context.Background()
 
 	form := NewCreateBoardForm()
@@ -207,6 +208,185 @@ func TestCreateBoard(t *testing.T) {
 	}
 }
 
+func TestDuplicateBoard(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{
+				Model:  Model{ID: 1},
+				Name:   "Original",
+				Width:  10,
+				Height: 20,
+			},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+
+	form := DuplicateBoardForm{Name: "  Copy  "}
+	board, err := service.DuplicateBoard(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("DuplicateBoard returned error: %+v", err)
+	}
+	assert := assert.New(t)
+	assert.ThatString(board.Name).IsEqualTo("Copy")
+	assert.ThatInt(board.Width).IsEqualTo(10)
+	assert.ThatString(repo.Boards[0].Name).IsEqualTo("Original")
+
+	form = DuplicateBoardForm{Name: ""}
+	_, err = service.DuplicateBoard(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("DuplicateBoard with blank name should have returned ErrInvalidForm, was: %+v", err)
+	}
+
+	form = DuplicateBoardForm{Name: "Copy"}
+	_, err = service.DuplicateBoard(ctx, "2", &form)
+	if !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("DuplicateBoard of a missing board should have returned RecordNotFound, was: %+v", err)
+	}
+
+	repo.ErrorResult = ErrNameTaken
+	form = DuplicateBoardForm{Name: "Original"}
+	_, err = service.DuplicateBoard(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("DuplicateBoard should have returned ErrInvalidForm, was: %+v", err)
+	}
+	if _, ok := form.Errors["Name"]; !ok {
+		t.Error("No error for 'Name' was found in form")
+	}
+}
+
+func TestPublishBoard(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{
+				Model:  Model{ID: 1},
+				Name:   "Board 1",
+				Width:  10,
+				Height: 20,
+			},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+
+	first, err := service.PublishBoard(ctx, "1")
+	if err != nil {
+		t.Fatalf("PublishBoard returned error: %+v", err)
+	}
+	assert := assert.New(t)
+	assert.ThatInt(first.Number).IsEqualTo(1)
+	assert.That(first.BoardID).IsEqualTo(ID(1))
+	assert.ThatString(first.Snapshot.Board.Name).IsEqualTo("Board 1")
+	assert.ThatInt(first.Snapshot.Version).IsEqualTo(BoardDocumentVersion)
+
+	second, err := service.PublishBoard(ctx, "1")
+	if err != nil {
+		t.Fatalf("PublishBoard returned error: %+v", err)
+	}
+	assert.ThatInt(second.Number).IsEqualTo(2)
+
+	found, err := service.FindBoardVersion(ctx, "1", "2")
+	if err != nil {
+		t.Fatalf("FindBoardVersion returned error: %+v", err)
+	}
+	assert.That(found.ID).IsEqualTo(second.ID)
+
+	for _, number := range []string{"0", "-1", "0x2", "two"} {
+		if _, err = service.FindBoardVersion(ctx, "1", number); !errors.Is(ErrInvalidIDString{}, err) {
+			t.Errorf("FindBoardVersion of version %q should have returned ErrInvalidIDString, was: %+v", number, err)
+		}
+	}
+
+	_, err = service.PublishBoard(ctx, "2")
+	if !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("PublishBoard of a missing board should have returned RecordNotFound, was: %+v", err)
+	}
+}
+
+func TestUndoRedoBoardUpdate(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{
+				Model:  Model{ID: 1},
+				Name:   "Original Name",
+				Width:  10,
+				Height: 20,
+			},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	form := NewBoardNameForm(&repo.Boards[0])
+	form.Name = "New Name"
+	if _, err := service.UpdateName(ctx, "1", &form); err != nil {
+		t.Fatalf("UpdateName returned error: %+v", err)
+	}
+
+	// Saving without changing anything is not recorded
+	form = NewBoardNameForm(&repo.Boards[0])
+	if _, err := service.UpdateName(ctx, "1", &form); err != nil {
+		t.Fatalf("UpdateName returned error: %+v", err)
+	}
+	assert.ThatInt(len(repo.BoardCommands)).IsEqualTo(1)
+	assert.That(repo.BoardCommands[0].Kind).IsEqualTo(CommandUpdateBoard)
+
+	if _, err := service.Undo(ctx, "1"); err != nil {
+		t.Fatalf("Undo returned error: %+v", err)
+	}
+	assert.ThatString(repo.Boards[0].Name).IsEqualTo("Original Name")
+
+	if _, err := service.Undo(ctx, "1"); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo with nothing left to undo should have returned ErrNothingToUndo, was: %+v", err)
+	}
+
+	if _, err := service.Redo(ctx, "1"); err != nil {
+		t.Fatalf("Redo returned error: %+v", err)
+	}
+	assert.ThatString(repo.Boards[0].Name).IsEqualTo("New Name")
+
+	if _, err := service.Redo(ctx, "1"); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo with nothing left to redo should have returned ErrNothingToRedo, was: %+v", err)
+	}
+
+	if _, err := service.Undo(ctx, "2"); !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("Undo on a missing board should have returned RecordNotFound, was: %+v", err)
+	}
+}
+
+func TestUpdateRejectsStaleVersion(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{
+				Model:   Model{ID: 1},
+				Name:    "Original Name",
+				Width:   10,
+				Height:  20,
+				Version: 3,
+			},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+
+	form := NewUpdateBoardForm(&repo.Boards[0])
+	form.Name = "New Name"
+	form.ExpectedVersion = 2
+	_, err := service.Update(ctx, "1", &form)
+	if !errors.Is(StaleRecord{}, err) {
+		t.Errorf("Update with an old version should have returned StaleRecord, was: %+v", err)
+	}
+	if repo.Boards[0].Name != "Original Name" {
+		t.Error("Board should not have been changed")
+	}
+
+	form.ExpectedVersion = 3
+	if _, err = service.Update(ctx, "1", &form); err != nil {
+		t.Errorf("Update with the current version returned error: %+v", err)
+	}
+}
+
 func TestUpdateName(t *testing.T) {
 	now := time.Now()
 	repo := fakeBoardCrudRepository{
@@ -224,7 +404,7 @@ func TestUpdateName(t *testing.T) {
 			},
 		},
 	}
-	service := NewBoardEditorService(&repo)
+	service := NewBoardEditorService(&repo, nil, nil)
 	ctx := context.Background()
 
 	form := NewBoardNameForm(&repo.Boards[0])
@@ -284,7 +464,7 @@ func TestUpdateDimensions(t *testing.T) {
 			},
 		},
 	}
-	service := NewBoardEditorService(&repo)
+	service := NewBoardEditorService(&repo, nil, nil)
 	ctx := context.Background()
 
 	form := NewUpdateBoardForm(&repo.Boards[0])
@@ -320,7 +500,7 @@ func TestUpdateDimensions(t *testing.T) {
 
 func TestDeleteByID(t *testing.T) {
 	repo := fakeBoardCrudRepository{}
-	service := NewBoardEditorService(&repo)
+	service := NewBoardEditorService(&repo, nil, nil)
 	ctx := context.Background()
 
 	err := service.DeleteByID(ctx, "1")
@@ -335,11 +515,269 @@ func TestDeleteByID(t *testing.T) {
 	}
 }
 
+func TestAddCitySpace(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Cities: []City{
+			{Model: Model{ID: 1}, BoardID: 1, Name: "City 1"},
+		},
+		CitySpaces: []CitySpace{
+			{Model: Model{ID: 1}, CityID: 1, Order: 1, SpaceType: TraderID, RequiredPrivilege: 1},
+			{Model: Model{ID: 2}, CityID: 1, Order: 2, SpaceType: MerchantID, RequiredPrivilege: 2},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	form := AddCitySpaceForm{SpaceType: TraderID, RequiredPrivilege: 3}
+	space, err := service.AddCitySpace(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("AddCitySpace with valid input returned error: %+v", err)
+	}
+	assert.That(space.CityID).IsEqualTo(ID(1))
+	assert.ThatInt(space.Order).IsEqualTo(3)
+	assert.That(space.SpaceType).IsEqualTo(TraderID)
+	assert.ThatInt(space.RequiredPrivilege).IsEqualTo(3)
+
+	form = AddCitySpaceForm{SpaceType: 3, RequiredPrivilege: 5}
+	_, err = service.AddCitySpace(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("AddCitySpace with invalid input should have returned ErrInvalidForm, was: %+v", err)
+	}
+	if _, ok := form.Errors["SpaceType"]; !ok {
+		t.Error("No error for 'SpaceType' was found in form")
+	}
+	if _, ok := form.Errors["RequiredPrivilege"]; !ok {
+		t.Error("No error for 'RequiredPrivilege' was found in form")
+	}
+
+	form = AddCitySpaceForm{SpaceType: TraderID, RequiredPrivilege: 1}
+	_, err = service.AddCitySpace(ctx, "2", &form)
+	if !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("AddCitySpace on a missing city should have returned RecordNotFound, was: %+v", err)
+	}
+}
+
+func TestUpdateCitySpace(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		SingletonCityResult: &City{Model: Model{ID: 1}, BoardID: 1},
+		CitySpaces: []CitySpace{
+			{Model: Model{ID: 1}, CityID: 1, Order: 1, SpaceType: TraderID, RequiredPrivilege: 1},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	form := UpdateCitySpaceForm{SpaceType: MerchantID, RequiredPrivilege: 4}
+	space, err := service.UpdateCitySpace(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("UpdateCitySpace with valid input returned error: %+v", err)
+	}
+	assert.That(space.SpaceType).IsEqualTo(MerchantID)
+	assert.ThatInt(space.RequiredPrivilege).IsEqualTo(4)
+	assert.ThatInt(len(repo.BoardCommands)).IsEqualTo(1)
+	assert.That(repo.BoardCommands[0].Kind).IsEqualTo(CommandUpdateCitySpace)
+
+	form = UpdateCitySpaceForm{SpaceType: MerchantID, RequiredPrivilege: 0}
+	_, err = service.UpdateCitySpace(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("UpdateCitySpace with invalid privilege should have returned ErrInvalidForm, was: %+v", err)
+	}
+}
+
+func TestReorderCitySpaces(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Cities: []City{
+			{Model: Model{ID: 1}, BoardID: 1, Name: "City 1"},
+		},
+		CitySpaces: []CitySpace{
+			{Model: Model{ID: 1}, CityID: 1, Order: 1},
+			{Model: Model{ID: 2}, CityID: 1, Order: 2},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+
+	form := ReorderCitySpacesForm{SpaceIDs: []ID{2, 1}}
+	if _, err := service.ReorderCitySpaces(ctx, "1", &form); err != nil {
+		t.Fatalf("ReorderCitySpaces with valid input returned error: %+v", err)
+	}
+
+	form = ReorderCitySpacesForm{SpaceIDs: []ID{2}}
+	_, err := service.ReorderCitySpaces(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("ReorderCitySpaces missing a space should have returned ErrInvalidForm, was: %+v", err)
+	}
+
+	form = ReorderCitySpacesForm{SpaceIDs: []ID{2, 2}}
+	_, err = service.ReorderCitySpaces(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("ReorderCitySpaces with a repeated space should have returned ErrInvalidForm, was: %+v", err)
+	}
+
+	form = ReorderCitySpacesForm{SpaceIDs: []ID{2, 3}}
+	_, err = service.ReorderCitySpaces(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("ReorderCitySpaces with a foreign space should have returned ErrInvalidForm, was: %+v", err)
+	}
+}
+
+func TestCreateRoute(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{Model: Model{ID: 1}, Name: "Board 1"},
+			{Model: Model{ID: 2}, Name: "Board 2"},
+		},
+		Cities: []City{
+			{Model: Model{ID: 1}, BoardID: 1, Name: "City 1"},
+			{Model: Model{ID: 2}, BoardID: 1, Name: "City 2"},
+			{Model: Model{ID: 3}, BoardID: 2, Name: "City 3"},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	form := RouteForm{StartCityID: 1, EndCityID: 2}
+	route, err := service.CreateRoute(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("CreateRoute with valid cities returned error: %+v", err)
+	}
+	assert.That(route.BoardID).IsEqualTo(ID(1))
+	assert.That(route.StartCityID).IsEqualTo(ID(1))
+	assert.That(route.EndCityID).IsEqualTo(ID(2))
+
+	form = RouteForm{StartCityID: 1, EndCityID: 1}
+	_, err = service.CreateRoute(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("CreateRoute with a self-loop should have returned ErrInvalidForm, was: %+v", err)
+	}
+	if _, ok := form.Errors["EndCityID"]; !ok {
+		t.Error("No error for 'EndCityID' was found in form")
+	}
+
+	form = RouteForm{StartCityID: 1, EndCityID: 3}
+	_, err = service.CreateRoute(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("CreateRoute across boards should have returned ErrInvalidForm, was: %+v", err)
+	}
+	if _, ok := form.Errors["EndCityID"]; !ok {
+		t.Error("No error for 'EndCityID' was found in form")
+	}
+
+	form = RouteForm{StartCityID: 99, EndCityID: 2}
+	_, err = service.CreateRoute(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("CreateRoute with a missing city should have returned ErrInvalidForm, was: %+v", err)
+	}
+	if _, ok := form.Errors["StartCityID"]; !ok {
+		t.Error("No error for 'StartCityID' was found in form")
+	}
+
+	form = RouteForm{StartCityID: 1, EndCityID: 2}
+	_, err = service.CreateRoute(ctx, "3", &form)
+	if !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("CreateRoute on a missing board should have returned RecordNotFound, was: %+v", err)
+	}
+}
+
+func TestUpdateRoute(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Cities: []City{
+			{Model: Model{ID: 1}, BoardID: 1, Name: "City 1"},
+			{Model: Model{ID: 2}, BoardID: 1, Name: "City 2"},
+			{Model: Model{ID: 3}, BoardID: 1, Name: "City 3"},
+			{Model: Model{ID: 4}, BoardID: 2, Name: "City 4"},
+		},
+		Routes: []Route{
+			{Model: Model{ID: 1}, BoardID: 1, StartCityID: 1, EndCityID: 2},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+
+	form := RouteForm{StartCityID: 1, EndCityID: 3}
+	route, err := service.UpdateRoute(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("UpdateRoute with valid cities returned error: %+v", err)
+	}
+	assert.New(t).That(route.EndCityID).IsEqualTo(ID(3))
+
+	form = RouteForm{StartCityID: 4, EndCityID: 3}
+	_, err = service.UpdateRoute(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("UpdateRoute across boards should have returned ErrInvalidForm, was: %+v", err)
+	}
+
+	_, err = service.UpdateRoute(ctx, "2", &form)
+	if !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("UpdateRoute with missing route should have returned RecordNotFound, was: %+v", err)
+	}
+}
+
+func TestUpdateRouteSpaces(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Routes: []Route{
+			{
+				Model:       Model{ID: 1},
+				BoardID:     1,
+				StartCityID: 1,
+				EndCityID:   2,
+				RouteSpaces: []RouteSpace{
+					{Model: Model{ID: 10}, RouteID: 1, Order: 1},
+					{Model: Model{ID: 11}, RouteID: 1, Order: 2},
+				},
+			},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	form := RouteSpacesForm{
+		TavernFlag: true,
+		Spaces:     []RouteSpaceForm{{ID: 11}, {ID: 0}, {ID: 10}},
+	}
+	route, err := service.UpdateRouteSpaces(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("UpdateRouteSpaces with valid ordering returned error: %+v", err)
+	}
+	assert.ThatBool(route.TavernFlag).IsTrue()
+	assert.ThatInt(len(route.RouteSpaces)).IsEqualTo(3)
+	assert.That(route.RouteSpaces[0].ID).IsEqualTo(ID(11))
+	assert.ThatInt(route.RouteSpaces[0].Order).IsEqualTo(1)
+	assert.That(route.RouteSpaces[1].ID).IsEqualTo(ID(0))
+	assert.ThatInt(route.RouteSpaces[1].Order).IsEqualTo(2)
+	assert.That(route.RouteSpaces[2].ID).IsEqualTo(ID(10))
+	assert.ThatInt(route.RouteSpaces[2].Order).IsEqualTo(3)
+
+	form = RouteSpacesForm{Spaces: []RouteSpaceForm{{ID: 10}, {ID: 10}}}
+	_, err = service.UpdateRouteSpaces(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("UpdateRouteSpaces with a repeated space should have returned ErrInvalidForm, was: %+v", err)
+	}
+
+	form = RouteSpacesForm{Spaces: []RouteSpaceForm{{ID: 99}}}
+	_, err = service.UpdateRouteSpaces(ctx, "1", &form)
+	if !errors.Is(ErrInvalidForm, err) {
+		t.Errorf("UpdateRouteSpaces with another route's space should have returned ErrInvalidForm, was: %+v", err)
+	}
+	if _, ok := form.Errors["Spaces"]; !ok {
+		t.Error("No error for 'Spaces' was found in form")
+	}
+}
+
 type fakeBoardCrudRepository struct {
 	Boards []Board
+	Cities []City
 	SingletonCityResult *City
 	MultipleCityResult []City
 	CitySpaces []CitySpace
+	Routes []Route
+	BoardVersions []BoardVersion
+	BoardCommands []BoardCommand
+	PrestigeTable *PrestigeTable
 	ErrorResult error
 }
 
@@ -352,6 +790,9 @@ func (r fakeBoardCrudRepository)GetBoardByID(ctx context.Context, id ID) (*Board
 
 	return nil, NewBoardNotFoundError(id)
 }
+func (r fakeBoardCrudRepository)GetBoardGraphByID(ctx context.Context, id ID) (*Board, error) {
+	return r.GetBoardByID(ctx, id)
+}
 func (r fakeBoardCrudRepository)CreateBoard(ctx context.Context, board *Board) error {
 	return r.ErrorResult
 }
@@ -365,14 +806,46 @@ func (r fakeBoardCrudRepository)UpdateBoard(ctx context.Context, id ID, updateFn
 func (r fakeBoardCrudRepository)ListBoards(ctx context.Context) ([]Board, error) {
 	return r.Boards, r.ErrorResult
 }
+func (r fakeBoardCrudRepository)SearchBoards(ctx context.Context, query BoardQuery) ([]Board, int, error) {
+	boards := make([]Board, 0)
+	for _, board := range r.Boards {
+		if strings.Contains(strings.ToLower(board.Name), strings.ToLower(query.Name)) {
+			boards = append(boards, board)
+		}
+	}
+	total := len(boards)
+	if query.Offset >= total {
+		return []Board{}, total, r.ErrorResult
+	}
+	boards = boards[query.Offset:]
+	if len(boards) > query.Limit {
+		boards = boards[:query.Limit]
+	}
+	return boards, total, r.ErrorResult
+}
 func (r fakeBoardCrudRepository)DeleteBoardByID(ctx context.Context, id ID) error {
 	return r.ErrorResult
 }
+func (r fakeBoardCrudRepository)GetBoardDeleteImpact(ctx context.Context, id ID) (*BoardDeleteImpact, error) {
+	board, err := r.GetBoardByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &BoardDeleteImpact{BoardID: board.ID, Cities: len(r.Cities), Routes: len(r.Routes), Deletable: true}, r.ErrorResult
+}
 
 func (r fakeBoardCrudRepository)ListCitiesByBoardID(ctx context.Context, boardID ID) ([]City, error) {
 	return r.MultipleCityResult, r.ErrorResult
 }
 func (r fakeBoardCrudRepository)GetCityByID(ctx context.Context, id ID) (*City, error) {
+	if r.Cities != nil {
+		for _, city := range r.Cities {
+			if city.ID == id {
+				return &city, nil
+			}
+		}
+		return nil, NewRecordNotFoundError("City", id)
+	}
 	return r.SingletonCityResult, r.ErrorResult
 }
 func (r fakeBoardCrudRepository)CreateCity(ctx context.Context, city *City) error {
@@ -395,16 +868,161 @@ func (r fakeBoardCrudRepository) DeleteCityByID(ctx context.Context, id ID) erro
 func (r fakeBoardCrudRepository)CreateCitySpace(context.Context, *CitySpace) error {
 	return r.ErrorResult
 }
-func (r fakeBoardCrudRepository)UpdateCitySpace(ctx context.Context, id ID, updateFn func (space *CitySpace) (*CitySpace, error)) error {
-	_, err := updateFn(&r.CitySpaces[0])
+func (r fakeBoardCrudRepository)UpdateCitySpace(ctx context.Context, id ID, updateFn func (space *CitySpace) (*CitySpace, error)) (*CitySpace, error) {
+	updatedSpace, err := updateFn(&r.CitySpaces[0])
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return r.ErrorResult
+	return updatedSpace, r.ErrorResult
 }
 func (r fakeBoardCrudRepository)GetCitySpacesByCityID(ctx context.Context, cityID ID) ([]CitySpace, error) {
 	return r.CitySpaces, r.ErrorResult
 }
+func (r fakeBoardCrudRepository)GetCitySpaceByID(ctx context.Context, id ID) (*CitySpace, error) {
+	for _, space := range r.CitySpaces {
+		if space.ID == id {
+			return &space, nil
+		}
+	}
+	return nil, NewRecordNotFoundError("CitySpace", id)
+}
 func (r fakeBoardCrudRepository)DeleteCitySpaceByID(ctx context.Context, id ID) error{
 	return r.ErrorResult
 }
+func (r fakeBoardCrudRepository)ReorderCitySpaces(ctx context.Context, cityID ID, spaceIDs []ID) ([]CitySpace, error) {
+	return r.CitySpaces, r.ErrorResult
+}
+func (r fakeBoardCrudRepository)RestoreCitySpaces(ctx context.Context, cityID ID, spaces []CitySpace) error {
+	return r.ErrorResult
+}
+
+func (r fakeBoardCrudRepository)ListRoutesByBoardID(ctx context.Context, boardID ID) ([]Route, error) {
+	return r.Routes, r.ErrorResult
+}
+func (r fakeBoardCrudRepository)GetRouteByID(ctx context.Context, id ID) (*Route, error) {
+	for _, route := range r.Routes {
+		if route.ID == id {
+			return &route, nil
+		}
+	}
+	return nil, NewRecordNotFoundError("Route", id)
+}
+func (r fakeBoardCrudRepository)CreateRoute(ctx context.Context, route *Route) error {
+	return r.ErrorResult
+}
+func (r fakeBoardCrudRepository)UpdateRoute(ctx context.Context, id ID, updateFn func (route *Route) (*Route, error)) (*Route, error) {
+	route, err := r.GetRouteByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updatedRoute, err := updateFn(route)
+	if err != nil {
+		return nil, err
+	}
+	return updatedRoute, r.ErrorResult
+}
+func (r fakeBoardCrudRepository)DeleteRouteByID(ctx context.Context, id ID) error {
+	return r.ErrorResult
+}
+func (r fakeBoardCrudRepository)UpdateRouteSpaces(ctx context.Context, routeID ID, updateFn func (route *Route) (*Route, error)) (*Route, error) {
+	return r.UpdateRoute(ctx, routeID, updateFn)
+}
+func (r fakeBoardCrudRepository)RestoreRoute(ctx context.Context, route *Route) error {
+	return r.ErrorResult
+}
+func (r fakeBoardCrudRepository)GetPrestigeTableByBoardID(ctx context.Context, boardID ID) (*PrestigeTable, error) {
+	if r.PrestigeTable == nil || r.PrestigeTable.BoardID != boardID {
+		return nil, NewRecordNotFoundError("PrestigeTable", boardID)
+	}
+	return r.PrestigeTable, r.ErrorResult
+}
+func (r *fakeBoardCrudRepository)SavePrestigeTable(ctx context.Context, table *PrestigeTable) error {
+	if r.ErrorResult != nil {
+		return r.ErrorResult
+	}
+	r.PrestigeTable = table
+	return nil
+}
+func (r *fakeBoardCrudRepository)DeletePrestigeTableByBoardID(ctx context.Context, boardID ID) error {
+	if r.PrestigeTable == nil || r.PrestigeTable.BoardID != boardID {
+		return NewRecordNotFoundError("PrestigeTable", boardID)
+	}
+	r.PrestigeTable = nil
+	return r.ErrorResult
+}
+func (r fakeBoardCrudRepository)CreateBoardGraph(ctx context.Context, board *Board) error {
+	return r.ErrorResult
+}
+func (r *fakeBoardCrudRepository)CreateBoardVersion(ctx context.Context, version *BoardVersion) error {
+	if r.ErrorResult != nil {
+		return r.ErrorResult
+	}
+	version.ID = ID(len(r.BoardVersions) + 1)
+	version.Number = 1
+	for _, existing := range r.BoardVersions {
+		if existing.BoardID == version.BoardID && existing.Number >= version.Number {
+			version.Number = existing.Number + 1
+		}
+	}
+	r.BoardVersions = append(r.BoardVersions, *version)
+	return nil
+}
+func (r fakeBoardCrudRepository)ListBoardVersionsByBoardID(ctx context.Context, boardID ID) ([]BoardVersion, error) {
+	var versions []BoardVersion
+	for _, version := range r.BoardVersions {
+		if version.BoardID == boardID {
+			versions = append(versions, version)
+		}
+	}
+	return versions, r.ErrorResult
+}
+func (r fakeBoardCrudRepository)GetBoardVersionByNumber(ctx context.Context, boardID ID, number int) (*BoardVersion, error) {
+	for _, version := range r.BoardVersions {
+		if version.BoardID == boardID && version.Number == number {
+			return &version, nil
+		}
+	}
+	return nil, NewRecordNotFoundError("BoardVersion", ID(number))
+}
+func (r *fakeBoardCrudRepository)Transaction(ctx context.Context, fn func(repo BoardCrudRepository) error) error {
+	return fn(r)
+}
+func (r fakeBoardCrudRepository)RestoreCity(ctx context.Context, city *City, routes []Route) error {
+	return r.ErrorResult
+}
+func (r *fakeBoardCrudRepository)CreateBoardCommand(ctx context.Context, command *BoardCommand) error {
+	commands := r.BoardCommands[:0]
+	for _, existing := range r.BoardCommands {
+		if existing.BoardID != command.BoardID || !existing.Undone {
+			commands = append(commands, existing)
+		}
+	}
+	command.ID = ID(len(commands) + 1)
+	r.BoardCommands = append(commands, *command)
+	return nil
+}
+func (r fakeBoardCrudRepository)GetLastBoardCommand(ctx context.Context, boardID ID) (*BoardCommand, error) {
+	for i := len(r.BoardCommands) - 1; i >= 0; i-- {
+		if r.BoardCommands[i].BoardID == boardID && !r.BoardCommands[i].Undone {
+			return &r.BoardCommands[i], nil
+		}
+	}
+	return nil, NewRecordNotFoundError("BoardCommand", boardID)
+}
+func (r fakeBoardCrudRepository)GetLastUndoneBoardCommand(ctx context.Context, boardID ID) (*BoardCommand, error) {
+	for i := range r.BoardCommands {
+		if r.BoardCommands[i].BoardID == boardID && r.BoardCommands[i].Undone {
+			return &r.BoardCommands[i], nil
+		}
+	}
+	return nil, NewRecordNotFoundError("BoardCommand", boardID)
+}
+func (r fakeBoardCrudRepository)SetBoardCommandUndone(ctx context.Context, id ID, undone bool) error {
+	for i := range r.BoardCommands {
+		if r.BoardCommands[i].ID == id {
+			r.BoardCommands[i].Undone = undone
+			return nil
+		}
+	}
+	return NewRecordNotFoundError("BoardCommand", id)
+}
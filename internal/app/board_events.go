@@ -0,0 +1,106 @@
+package app
+
+import "sync"
+
+// BoardEventType identifies the kind of change a BoardEvent announces
+type BoardEventType string
+
+const (
+	// BoardEventCityCreated Data is the new City
+	BoardEventCityCreated BoardEventType = "cityCreated"
+	// BoardEventCityUpdated Data is the updated City
+	BoardEventCityUpdated BoardEventType = "cityUpdated"
+	// BoardEventCityDeleted Data is a DeletedCity
+	BoardEventCityDeleted BoardEventType = "cityDeleted"
+	// BoardEventBoardResized Data is the Board, without its cities or routes
+	BoardEventBoardResized BoardEventType = "boardResized"
+	// BoardEventBoardRestored Data is the whole Board after an undo or redo, which may change anything on it
+	BoardEventBoardRestored BoardEventType = "boardRestored"
+	// BoardEventPrestigeTableUpdated Data is the board's new PrestigeTable, or null once it has been removed
+	BoardEventPrestigeTableUpdated BoardEventType = "prestigeTableUpdated"
+)
+
+// BoardEvent A change made to a board, announced to everyone looking at it once the change is committed.
+// Data has the same JSON shape the admin controllers respond with for the change.
+type BoardEvent struct {
+	Type    BoardEventType `json:"type"`
+	BoardID ID             `json:"boardId"`
+	Data    interface{}    `json:"data"`
+}
+
+// DeletedCity Data for BoardEventCityDeleted
+type DeletedCity struct {
+	ID      ID `json:"id"`
+	BoardID ID `json:"boardId"`
+}
+
+// BoardEventPublisher is notified by BoardEditorService of every change it makes
+type BoardEventPublisher interface {
+	Publish(event BoardEvent)
+}
+
+// BoardEventSubscriber lets a caller follow the changes made to one board.
+// The caller must call unsubscribe when it stops reading from events.
+type BoardEventSubscriber interface {
+	Subscribe(boardID ID) (events <-chan BoardEvent, unsubscribe func())
+}
+
+// boardEventBufferSize How many events a subscriber may fall behind by before it starts missing them
+const boardEventBufferSize = 64
+
+// BoardEventBroker In-process BoardEventPublisher and BoardEventSubscriber.
+// Publish never blocks; a subscriber that isn't keeping up misses events rather than holding up the editor.
+type BoardEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[ID]map[chan BoardEvent]struct{}
+}
+
+func NewBoardEventBroker() *BoardEventBroker {
+	return &BoardEventBroker{
+		subscribers: make(map[ID]map[chan BoardEvent]struct{}),
+	}
+}
+
+func (b *BoardEventBroker) Publish(event BoardEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers[event.BoardID] {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (b *BoardEventBroker) Subscribe(boardID ID) (<-chan BoardEvent, func()) {
+	events := make(chan BoardEvent, boardEventBufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[boardID] == nil {
+		b.subscribers[boardID] = make(map[chan BoardEvent]struct{})
+	}
+	b.subscribers[boardID][events] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers[boardID], events)
+			if len(b.subscribers[boardID]) == 0 {
+				delete(b.subscribers, boardID)
+			}
+			close(events)
+		})
+	}
+
+	return events, unsubscribe
+}
+
+// noBoardEvents Publisher for when nobody is listening
+type noBoardEvents struct{}
+
+func (noBoardEvents) Publish(BoardEvent) {}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"github.com/assertgo/assert"
+	"testing"
+)
+
+func TestBoardEventBrokerOnlyDeliversToSubscribersOfTheBoard(t *testing.T) {
+	broker := NewBoardEventBroker()
+	board1Events, unsubscribe1 := broker.Subscribe(1)
+	board2Events, unsubscribe2 := broker.Subscribe(2)
+	defer unsubscribe2()
+
+	broker.Publish(BoardEvent{Type: BoardEventCityDeleted, BoardID: 1, Data: DeletedCity{ID: 5, BoardID: 1}})
+
+	assert := assert.New(t)
+	select {
+	case event := <-board1Events:
+		assert.That(event.Type).IsEqualTo(BoardEventCityDeleted)
+		assert.That(event.Data).IsEqualTo(DeletedCity{ID: 5, BoardID: 1})
+	default:
+		t.Error("subscriber to board 1 did not receive the event")
+	}
+
+	select {
+	case event := <-board2Events:
+		t.Errorf("subscriber to board 2 received an event for board 1: %+v", event)
+	default:
+	}
+
+	unsubscribe1()
+	unsubscribe1()
+	if _, open := <-board1Events; open {
+		t.Error("unsubscribing should close the channel")
+	}
+	broker.Publish(BoardEvent{Type: BoardEventCityDeleted, BoardID: 1})
+}
+
+func TestBoardEventBrokerDoesNotBlockOnSlowSubscribers(t *testing.T) {
+	broker := NewBoardEventBroker()
+	_, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i < boardEventBufferSize*2; i++ {
+		broker.Publish(BoardEvent{Type: BoardEventCityUpdated, BoardID: 1})
+	}
+}
+
+func TestUpdateCityPublishesEvent(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}}},
+		SingletonCityResult: &City{
+			Model:   Model{ID: 3},
+			BoardID: 1,
+			Name:    "Old Name",
+		},
+	}
+	broker := NewBoardEventBroker()
+	events, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+	service := NewBoardEditorService(&repo, broker, nil)
+
+	form := CityForm{Name: "New Name", Position: Position{X: 10, Y: 20}}
+	if _, err := service.UpdateCity(context.Background(), "3", &form); err != nil {
+		t.Fatalf("UpdateCity returned error: %+v", err)
+	}
+
+	assert := assert.New(t)
+	select {
+	case event := <-events:
+		assert.That(event.Type).IsEqualTo(BoardEventCityUpdated)
+		assert.That(event.BoardID).IsEqualTo(ID(1))
+		city, ok := event.Data.(*City)
+		if !ok {
+			t.Fatalf("event data should be the city, was: %+v", event.Data)
+		}
+		assert.ThatString(city.Name).IsEqualTo("New Name")
+	default:
+		t.Error("UpdateCity did not publish an event")
+	}
+
+	repo.ErrorResult = ErrNameTaken
+	if _, err := service.UpdateCity(context.Background(), "3", &form); err == nil {
+		t.Fatal("UpdateCity should have returned the repository's error")
+	}
+	select {
+	case event := <-events:
+		t.Errorf("a failed update should not publish an event: %+v", event)
+	default:
+	}
+}
@@ -18,6 +18,12 @@ type UpdateBoardForm struct {
 	Name   string `json:"name" schema:"name"`
 	Width  int    `json:"width" schema:"width"`
 	Height int    `json:"height" schema:"height"`
+	PlayerRangeForm
+	// ResizeMode and ResizeAnchor decide how cities move if the width or height changes
+	ResizeMode   ResizeMode        `json:"resizeMode" schema:"resizeMode"`
+	ResizeAnchor ResizeAnchorPoint `json:"resizeAnchor" schema:"resizeAnchor"`
+	// ExpectedVersion The version of the board the client last saw. Zero skips the check.
+	ExpectedVersion int `json:"-" schema:"-"`
 }
 
 func NewUpdateBoardForm(board *Board) UpdateBoardForm {
@@ -51,13 +57,50 @@ type CreateBoardForm struct {
 	Name string `json:"name" schema:"Name"`
 }
 
+// ImportBoardForm A board document to import, optionally under a different name than the one in the document
+type ImportBoardForm struct {
+	Form
+	Name     string
+	Document BoardDocument
+}
+
+// DuplicateBoardForm The name to give a copy of an existing board
+type DuplicateBoardForm struct {
+	Form `json:"-"`
+	Name string `json:"name" schema:"name"`
+}
+
+// BoardImageForm An uploaded background image for a board.
+// ResizeBoard sets the board's width and height to those of the image.
+type BoardImageForm struct {
+	Form        `json:"-"`
+	Data        []byte `json:"-" schema:"-"`
+	ResizeBoard bool   `json:"resizeBoard" schema:"resizeBoard"`
+}
+
 // CityForm JSON format in which cities will be posted from the board editor on create or update.
 // Cities are always valid as long as they relate to a board;
 // we let the user do whatever they want with them.
 type CityForm struct {
+	Form     `json:"-"`
 	ID       uint     `json:"id" schema:"id"`
 	Name     string   `json:"name" schema:"name"`
 	Position Position `json:"position" schema:"position"`
+	PlayerRangeForm
+	// Upgrade The ability the city upgrades. Left out, the city's upgrade isn't changed.
+	Upgrade *Ability `json:"upgrade" schema:"upgrade"`
+	// ExpectedVersion The version of the city the client last saw. Zero skips the check.
+	ExpectedVersion int `json:"-" schema:"-"`
+	// Warnings Problems with where the city was placed, found after it was saved
+	Warnings []CityWarning `json:"-" schema:"-"`
+}
+
+// ApplyUpgrade The city's upgrade with the form's change made to it
+func (f *CityForm) ApplyUpgrade(current Ability) Ability {
+	if f.Upgrade != nil {
+		return *f.Upgrade
+	}
+	return current
 }
 
 func (f *CityForm) NormalizeInputs() {
@@ -68,11 +111,13 @@ func (f *CityForm) IsValid() bool {
 	return true
 }
 
+// AddCitySpaceForm JSON format in which a new office space is posted from the board editor.
+// New spaces are always added after the city's existing spaces.
 type AddCitySpaceForm struct {
-	CityID            uint
-	SpaceType         TradesmanType
-	RequiredPrivilege int
-	Form
+	CityID            ID            `json:"cityId"`
+	SpaceType         TradesmanType `json:"spaceType"`
+	RequiredPrivilege int           `json:"requiredPrivilege"`
+	Form              `json:"-"`
 }
 
 func (f *AddCitySpaceForm) IsValid() bool {
@@ -92,10 +137,10 @@ func (f *AddCitySpaceForm) IsValid() bool {
 }
 
 type UpdateCitySpaceForm struct {
-	ID                uint
-	SpaceType         TradesmanType
-	RequiredPrivilege int
-	Form
+	ID                ID            `json:"id"`
+	SpaceType         TradesmanType `json:"spaceType"`
+	RequiredPrivilege int           `json:"requiredPrivilege"`
+	Form              `json:"-"`
 }
 
 func (f *UpdateCitySpaceForm) IsValid() bool {
@@ -107,5 +152,98 @@ func (f *UpdateCitySpaceForm) IsValid() bool {
 		f.AddError("SpaceType", "is invalid")
 	}
 
-	return f.HasError()
+	return !f.HasError()
+}
+
+// ReorderCitySpacesForm JSON format in which the board editor posts the new order of all of a city's spaces
+type ReorderCitySpacesForm struct {
+	SpaceIDs []ID `json:"spaceIds"`
+	Form     `json:"-"`
+}
+
+// IsValidFor checks that the form lists every one of the city's spaces exactly once
+func (f *ReorderCitySpacesForm) IsValidFor(spaces []CitySpace) bool {
+	existing := make(map[ID]bool, len(spaces))
+	for _, space := range spaces {
+		existing[space.ID] = true
+	}
+
+	seen := make(map[ID]bool, len(f.SpaceIDs))
+	for _, id := range f.SpaceIDs {
+		if !existing[id] {
+			f.AddError("SpaceIDs", fmt.Sprintf("space %d does not belong to this city", id))
+		} else if seen[id] {
+			f.AddError("SpaceIDs", fmt.Sprintf("space %d is listed more than once", id))
+		}
+		seen[id] = true
+	}
+
+	if len(seen) != len(existing) {
+		f.AddError("SpaceIDs", "must include every space in the city")
+	}
+
+	return !f.HasError()
+}
+
+// RouteForm JSON format in which routes will be posted from the board editor on create or update.
+// A route must connect two different cities on the same board.
+type RouteForm struct {
+	Form        `json:"-"`
+	ID          ID `json:"id" schema:"id"`
+	StartCityID ID `json:"startCityId" schema:"startCityId"`
+	EndCityID   ID `json:"endCityId" schema:"endCityId"`
+	PlayerRangeForm
+}
+
+func (f *RouteForm) IsValid() bool {
+	if f.StartCityID == 0 {
+		f.AddError("StartCityID", "is required")
+	}
+
+	if f.EndCityID == 0 {
+		f.AddError("EndCityID", "is required")
+	}
+
+	if f.StartCityID != 0 && f.StartCityID == f.EndCityID {
+		f.AddError("EndCityID", "must not be the same as the start city")
+	}
+
+	return !f.HasError()
+}
+
+// RouteSpacesForm JSON format in which the board editor posts the complete, ordered list of a route's spaces.
+// Existing spaces are identified by ID, and entries without an ID are added as new spaces.
+// Any existing space left out of the list is removed.
+type RouteSpacesForm struct {
+	Form       `json:"-"`
+	TavernFlag bool             `json:"tavernFlag"`
+	Spaces     []RouteSpaceForm `json:"spaces"`
+}
+
+type RouteSpaceForm struct {
+	ID ID `json:"id"`
+}
+
+// IsValidFor validates the desired ordering against the spaces the route currently has
+func (f *RouteSpacesForm) IsValidFor(route *Route) bool {
+	existing := make(map[ID]bool, len(route.RouteSpaces))
+	for _, space := range route.RouteSpaces {
+		existing[space.ID] = true
+	}
+
+	seen := make(map[ID]bool, len(f.Spaces))
+	for _, space := range f.Spaces {
+		if space.ID == 0 {
+			continue
+		}
+
+		if !existing[space.ID] {
+			f.AddError("Spaces", fmt.Sprintf("space %d does not belong to this route", space.ID))
+		} else if seen[space.ID] {
+			f.AddError("Spaces", fmt.Sprintf("space %d is listed more than once", space.ID))
+		}
+		seen[space.ID] = true
+	}
+
+	return !f.HasError()
 }
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"time"
+
+	// Register the formats accepted for board images with image.DecodeConfig
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// MaxBoardImageSize The largest board image that may be uploaded, in bytes
+const MaxBoardImageSize = 16 << 20
+
+type BoardImageService interface {
+	FindBoardImage(ctx context.Context, boardID string) (*BoardImage, error)
+	UploadBoardImage(ctx context.Context, boardID string, form *BoardImageForm) (*BoardImage, error)
+	DeleteBoardImage(ctx context.Context, boardID string) error
+}
+
+// NewBoardImageService Boards are resized through boardEditorService, so the change can be undone like any other
+func NewBoardImageService(store BoardImageStore, boardEditorService BoardEditorService) BoardImageService {
+	return &boardImageService{
+		store:  store,
+		boards: boardEditorService,
+	}
+}
+
+type boardImageService struct {
+	store  BoardImageStore
+	boards BoardEditorService
+}
+
+func (s boardImageService)FindBoardImage(ctx context.Context, boardID string) (*BoardImage, error) {
+	board, err := s.boards.FindByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.GetBoardImage(ctx, board.ID)
+}
+
+func (s boardImageService)UploadBoardImage(ctx context.Context, boardID string, form *BoardImageForm) (*BoardImage, error) {
+	board, err := s.boards.FindByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var config image.Config
+	var format string
+	if len(form.Data) == 0 {
+		form.AddError("Image", "is required")
+	} else if len(form.Data) > MaxBoardImageSize {
+		form.AddError("Image", "must be no larger than 16 MB")
+	} else if config, format, err = image.DecodeConfig(bytes.NewReader(form.Data)); err != nil {
+		form.AddError("Image", "must be a PNG, JPEG, or GIF image")
+	}
+
+	if form.HasError() {
+		return nil, ErrInvalidForm
+	}
+
+	checksum := sha256.Sum256(form.Data)
+	boardImage := BoardImage{
+		BoardID:     board.ID,
+		ContentType: "image/" + format,
+		Width:       config.Width,
+		Height:      config.Height,
+		Checksum:    hex.EncodeToString(checksum[:]),
+		UpdatedAt:   time.Now().UTC(),
+		Data:        form.Data,
+	}
+
+	// The board is resized first, so the old image is kept if the board can't be
+	if form.ResizeBoard {
+		_, err = s.boards.UpdateDimensions(ctx, boardID, &UpdateBoardForm{
+			Width:  boardImage.Width,
+			Height: boardImage.Height,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = s.store.SaveBoardImage(ctx, &boardImage); err != nil {
+		return nil, err
+	}
+
+	return &boardImage, nil
+}
+
+func (s boardImageService)DeleteBoardImage(ctx context.Context, boardID string) error {
+	board, err := s.boards.FindByID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+
+	return s.store.DeleteBoardImage(ctx, board.ID)
+}
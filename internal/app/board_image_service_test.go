@@ -0,0 +1,139 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestUploadBoardImage(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{Model: Model{ID: 1}, Name: "Board", Width: 10, Height: 20},
+		},
+	}
+	store := fakeBoardImageStore{}
+	service := NewBoardImageService(&store, NewBoardEditorService(&repo, nil, nil))
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewRGBA(image.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatal(err)
+	}
+
+	form := BoardImageForm{Data: data.Bytes()}
+	uploaded, err := service.UploadBoardImage(ctx, "1", &form)
+	if err != nil {
+		t.Fatalf("UploadBoardImage returned error: %+v", err)
+	}
+	assert.ThatString(uploaded.ContentType).IsEqualTo("image/png")
+	assert.ThatInt(uploaded.Width).IsEqualTo(300)
+	assert.ThatInt(uploaded.Height).IsEqualTo(200)
+	assert.ThatInt(len(uploaded.Checksum)).IsEqualTo(64)
+	assert.ThatInt(repo.Boards[0].Width).IsEqualTo(10)
+
+	found, err := service.FindBoardImage(ctx, "1")
+	if err != nil {
+		t.Fatalf("FindBoardImage returned error: %+v", err)
+	}
+	assert.ThatString(found.Checksum).IsEqualTo(uploaded.Checksum)
+
+	form = BoardImageForm{Data: data.Bytes(), ResizeBoard: true}
+	if _, err = service.UploadBoardImage(ctx, "1", &form); err != nil {
+		t.Fatalf("UploadBoardImage returned error: %+v", err)
+	}
+	assert.ThatInt(repo.Boards[0].Width).IsEqualTo(300)
+	assert.ThatInt(repo.Boards[0].Height).IsEqualTo(200)
+
+	var larger bytes.Buffer
+	if err = png.Encode(&larger, image.NewRGBA(image.Rect(0, 0, 600, 400))); err != nil {
+		t.Fatal(err)
+	}
+	repo.ErrorResult = errors.New("board could not be saved")
+	form = BoardImageForm{Data: larger.Bytes(), ResizeBoard: true}
+	if _, err = service.UploadBoardImage(ctx, "1", &form); err == nil {
+		t.Error("UploadBoardImage should have returned the error resizing the board")
+	}
+	repo.ErrorResult = nil
+	found, err = service.FindBoardImage(ctx, "1")
+	if err != nil {
+		t.Fatalf("FindBoardImage returned error: %+v", err)
+	}
+	assert.ThatInt(found.Width).IsEqualTo(300)
+
+	form = BoardImageForm{Data: []byte("not an image")}
+	if _, err = service.UploadBoardImage(ctx, "1", &form); !errors.Is(err, ErrInvalidForm) {
+		t.Errorf("Uploading something other than an image should have returned ErrInvalidForm, was: %+v", err)
+	}
+	assert.ThatInt(len(form.Errors["Image"])).IsEqualTo(1)
+
+	form = BoardImageForm{Data: data.Bytes()}
+	if _, err = service.UploadBoardImage(ctx, "2", &form); !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("Uploading to a missing board should have returned RecordNotFound, was: %+v", err)
+	}
+
+	if err = service.DeleteBoardImage(ctx, "1"); err != nil {
+		t.Fatalf("DeleteBoardImage returned error: %+v", err)
+	}
+	if _, err = service.FindBoardImage(ctx, "1"); !errors.Is(RecordNotFound{}, err) {
+		t.Errorf("Finding a deleted image should have returned RecordNotFound, was: %+v", err)
+	}
+}
+
+func TestDeleteBoardDeletesImage(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}, Name: "Board"}},
+	}
+	store := fakeBoardImageStore{Images: map[ID]BoardImage{1: {BoardID: 1}, 2: {BoardID: 2}}}
+	service := NewBoardEditorService(&repo, nil, &store)
+
+	if err := service.DeleteByID(context.Background(), "1"); err != nil {
+		t.Fatalf("DeleteByID returned error: %+v", err)
+	}
+	if _, found := store.Images[1]; found {
+		t.Error("Deleting the board should have deleted its image")
+	}
+	if _, found := store.Images[2]; !found {
+		t.Error("Deleting the board should not have deleted another board's image")
+	}
+
+	repo.ErrorResult = NewBoardInUseError(1, 1)
+	store.Images[1] = BoardImage{BoardID: 1}
+	if err := service.DeleteByID(context.Background(), "1"); err == nil {
+		t.Fatal("DeleteByID should have returned the repository's error")
+	}
+	if _, found := store.Images[1]; !found {
+		t.Error("A board that couldn't be deleted should keep its image")
+	}
+}
+
+type fakeBoardImageStore struct {
+	Images map[ID]BoardImage
+}
+
+func (s *fakeBoardImageStore) SaveBoardImage(ctx context.Context, image *BoardImage) error {
+	if s.Images == nil {
+		s.Images = make(map[ID]BoardImage)
+	}
+	s.Images[image.BoardID] = *image
+	return nil
+}
+
+func (s *fakeBoardImageStore) GetBoardImage(ctx context.Context, boardID ID) (*BoardImage, error) {
+	image, found := s.Images[boardID]
+	if !found {
+		return nil, NewRecordNotFoundError("BoardImage", boardID)
+	}
+	return &image, nil
+}
+
+func (s *fakeBoardImageStore) DeleteBoardImage(ctx context.Context, boardID ID) error {
+	delete(s.Images, boardID)
+	return nil
+}
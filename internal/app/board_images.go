@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// BoardImage A background image for a board, such as a map to lay the cities and routes out on
+type BoardImage struct {
+	BoardID     ID     `json:"boardId"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// Checksum identifies the contents of the image, so it can be used as an ETag
+	Checksum  string    `json:"checksum"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Data      []byte    `json:"-"`
+}
+
+// BoardImageStore Storage for board images, which are kept apart from the boards themselves since they can be large
+type BoardImageStore interface {
+	// SaveBoardImage replaces the board's image, if it has one
+	SaveBoardImage(ctx context.Context, image *BoardImage) error
+	// GetBoardImage returns RecordNotFound if the board has no image
+	GetBoardImage(ctx context.Context, boardID ID) (*BoardImage, error)
+	// DeleteBoardImage does nothing if the board has no image
+	DeleteBoardImage(ctx context.Context, boardID ID) error
+}
+
+// noBoardImages Store for when boards have no images
+type noBoardImages struct{}
+
+func (noBoardImages) SaveBoardImage(context.Context, *BoardImage) error {
+	return nil
+}
+
+func (noBoardImages) GetBoardImage(ctx context.Context, boardID ID) (*BoardImage, error) {
+	return nil, NewRecordNotFoundError("BoardImage", boardID)
+}
+
+func (noBoardImages) DeleteBoardImage(context.Context, ID) error {
+	return nil
+}
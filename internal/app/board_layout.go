@@ -0,0 +1,150 @@
+package app
+
+import "math"
+
+const (
+	// DefaultLayoutIterations How many steps LayoutCities takes when the form doesn't say
+	DefaultLayoutIterations = 300
+	// MaxLayoutIterations keeps a single layout request from tying up the server
+	MaxLayoutIterations = 2000
+	// layoutMargin The closest a laid out city may come to the edge of the board, so it is drawn entirely on the board
+	layoutMargin = 50
+)
+
+// LayoutBoardForm Options for laying out a board's cities.
+// DryRun computes the new positions without saving them, so they can be previewed.
+type LayoutBoardForm struct {
+	Form       `json:"-"`
+	DryRun     bool `json:"dryRun" schema:"dryRun"`
+	Iterations int  `json:"iterations" schema:"iterations"`
+}
+
+// BoardLayout Every city on a board, positioned by LayoutCities
+type BoardLayout struct {
+	BoardID ID     `json:"boardId"`
+	DryRun  bool   `json:"dryRun"`
+	Cities  []City `json:"cities"`
+}
+
+type layoutVector struct {
+	X, Y float64
+}
+
+// layoutCities Position the cities with the Fruchterman-Reingold force-directed algorithm.
+// Every city pushes every other city away, and routes pull the cities they connect back together,
+// so connected cities end up near each other and routes rarely cross. Cities start from where they
+// are now, which keeps the result close to any layout the user has already done, and which makes the
+// result the same every time for the same board. The positions are kept within the width and height.
+func layoutCities(cities []City, routes []Route, width, height int, iterations int) map[ID]Position {
+	positions := make(map[ID]Position, len(cities))
+	if len(cities) == 0 {
+		return positions
+	}
+
+	minX, maxX := layoutBounds(width)
+	minY, maxY := layoutBounds(height)
+	clamp := func(v layoutVector) layoutVector {
+		return layoutVector{
+			X: math.Max(minX, math.Min(maxX, v.X)),
+			Y: math.Max(minY, math.Min(maxY, v.Y)),
+		}
+	}
+
+	index := make(map[ID]int, len(cities))
+	current := make([]layoutVector, len(cities))
+	occupied := make(map[layoutVector]bool, len(cities))
+	center := layoutVector{X: (minX + maxX) / 2, Y: (minY + maxY) / 2}
+	radius := math.Min(maxX-minX, maxY-minY) / 3
+	for i, city := range cities {
+		index[city.ID] = i
+		current[i] = clamp(layoutVector{X: float64(city.Position.X), Y: float64(city.Position.Y)})
+
+		// New cities tend to be stacked on top of each other, and the forces between them would have no direction
+		if occupied[current[i]] {
+			angle := 2 * math.Pi * float64(i) / float64(len(cities))
+			current[i] = clamp(layoutVector{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)})
+		}
+		occupied[current[i]] = true
+	}
+
+	type edge struct{ start, end int }
+	var edges []edge
+	for _, route := range routes {
+		start, startFound := index[route.StartCityID]
+		end, endFound := index[route.EndCityID]
+		if startFound && endFound && start != end {
+			edges = append(edges, edge{start, end})
+		}
+	}
+
+	// k The ideal distance between cities, if they were spread evenly over the board
+	k := math.Sqrt((maxX - minX + 1) * (maxY - minY + 1) / float64(len(cities)))
+	initialTemperature := math.Max(maxX-minX, maxY-minY) / 10
+
+	displacement := make([]layoutVector, len(cities))
+	for iteration := 0; iteration < iterations; iteration++ {
+		for i := range displacement {
+			displacement[i] = layoutVector{}
+		}
+
+		for i := range current {
+			for j := i + 1; j < len(current); j++ {
+				direction, distance := layoutDirection(current[i], current[j], i, j)
+				force := k * k / distance
+				displacement[i].X += direction.X * force
+				displacement[i].Y += direction.Y * force
+				displacement[j].X -= direction.X * force
+				displacement[j].Y -= direction.Y * force
+			}
+		}
+
+		for _, e := range edges {
+			direction, distance := layoutDirection(current[e.start], current[e.end], e.start, e.end)
+			force := distance * distance / k
+			displacement[e.start].X -= direction.X * force
+			displacement[e.start].Y -= direction.Y * force
+			displacement[e.end].X += direction.X * force
+			displacement[e.end].Y += direction.Y * force
+		}
+
+		// Cities move less each step so the layout settles down
+		temperature := initialTemperature * (1 - float64(iteration)/float64(iterations))
+		for i := range current {
+			length := math.Hypot(displacement[i].X, displacement[i].Y)
+			if length == 0 {
+				continue
+			}
+			step := math.Min(length, temperature)
+			current[i] = clamp(layoutVector{
+				X: current[i].X + displacement[i].X/length*step,
+				Y: current[i].Y + displacement[i].Y/length*step,
+			})
+		}
+	}
+
+	for i, city := range cities {
+		positions[city.ID] = Position{
+			X: int(math.Round(current[i].X)),
+			Y: int(math.Round(current[i].Y)),
+		}
+	}
+	return positions
+}
+
+// layoutBounds The range of positions along a side of the board that keeps cities away from its edges
+func layoutBounds(size int) (float64, float64) {
+	margin := math.Min(layoutMargin, float64(size)/4)
+	return margin, float64(size) - margin
+}
+
+// layoutDirection The unit vector pointing from b to a, and the distance between them.
+// Cities in the same spot are pushed apart in a direction picked from their indexes.
+func layoutDirection(a, b layoutVector, i, j int) (layoutVector, float64) {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	distance := math.Hypot(dx, dy)
+	if distance < 0.01 {
+		angle := float64(i*31+j*17) * 0.618
+		return layoutVector{X: math.Cos(angle), Y: math.Sin(angle)}, 0.01
+	}
+	return layoutVector{X: dx / distance, Y: dy / distance}, distance
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"math"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestLayoutCities(t *testing.T) {
+	// Two triangles joined by a single route, with every city starting in the same spot
+	cities := make([]City, 6)
+	for i := range cities {
+		cities[i] = City{Model: Model{ID: ID(i + 1)}}
+	}
+	routes := []Route{
+		{StartCityID: 1, EndCityID: 2},
+		{StartCityID: 2, EndCityID: 3},
+		{StartCityID: 3, EndCityID: 1},
+		{StartCityID: 4, EndCityID: 5},
+		{StartCityID: 5, EndCityID: 6},
+		{StartCityID: 6, EndCityID: 4},
+		{StartCityID: 3, EndCityID: 4},
+		// Routes to cities that aren't on the board are ignored
+		{StartCityID: 1, EndCityID: 99},
+	}
+
+	positions := layoutCities(cities, routes, 800, 600, DefaultLayoutIterations)
+	assert := assert.New(t)
+	assert.ThatInt(len(positions)).IsEqualTo(6)
+
+	distinct := make(map[Position]bool)
+	for _, position := range positions {
+		distinct[position] = true
+		assert.ThatBool(position.X >= layoutMargin && position.X <= 800-layoutMargin).IsTrue()
+		assert.ThatBool(position.Y >= layoutMargin && position.Y <= 600-layoutMargin).IsTrue()
+	}
+	assert.ThatInt(len(distinct)).IsEqualTo(6)
+
+	distance := func(a, b ID) float64 {
+		return math.Hypot(float64(positions[a].X-positions[b].X), float64(positions[a].Y-positions[b].Y))
+	}
+	if distance(1, 2) >= distance(1, 5) {
+		t.Errorf("connected cities should be closer together than unconnected ones, got %+v", positions)
+	}
+
+	again := layoutCities(cities, routes, 800, 600, DefaultLayoutIterations)
+	assert.That(again).IsEqualTo(positions)
+}
+
+func TestLayoutCities_smallBoard(t *testing.T) {
+	cities := []City{
+		{Model: Model{ID: 1}, Position: Position{X: 500, Y: 500}},
+		{Model: Model{ID: 2}, Position: Position{X: -20, Y: 3}},
+	}
+
+	positions := layoutCities(cities, nil, 40, 20, 10)
+	for _, position := range positions {
+		if position.X < 0 || position.X > 40 || position.Y < 0 || position.Y > 20 {
+			t.Errorf("city should be on the board, was at %+v", position)
+		}
+	}
+}
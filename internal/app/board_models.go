@@ -32,7 +32,25 @@ type Board struct {
 	Name   string `json:"name"`
 	Width  int    `json:"width"`
 	Height int    `json:"height"`
+	// Version is incremented on every update, so clients can tell whether their copy is stale
+	Version int   `json:"version"`
+	// PlayerRange The numbers of players the board can be played with
+	PlayerRange
 	Cities []City `json:"cities"`
+	Routes []Route `json:"routes"`
+	// PrestigeTable The board's special scoring table, if it has one
+	PrestigeTable *PrestigeTable `json:"prestigeTable"`
+	// ConnectionObjective The board's bonus for linking two of its cities, if it has one
+	ConnectionObjective *ConnectionObjective `json:"connectionObjective"`
+}
+
+// BoardVersion An immutable, numbered snapshot of a board taken when it is published.
+// Games are played on a BoardVersion, so the board itself remains a draft that can be edited freely.
+type BoardVersion struct {
+	Model
+	BoardID  ID            `json:"boardId"`
+	Number   int           `json:"number"`
+	Snapshot BoardDocument `json:"snapshot"`
 }
 
 // Model is a simpler version of gorm.Model with JSON tags and without the DeletedAt column.
@@ -43,6 +61,18 @@ type Model struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// PlayerRange is a shared mixin for the parts of a board that are only in play for some numbers of players.
+// Zero means there is no minimum or maximum.
+type PlayerRange struct {
+	MinPlayers int `json:"minPlayers"`
+	MaxPlayers int `json:"maxPlayers"`
+}
+
+// Includes Whether the number of players is within the range
+func (r PlayerRange) Includes(players int) bool {
+	return (r.MinPlayers == 0 || players >= r.MinPlayers) && (r.MaxPlayers == 0 || players <= r.MaxPlayers)
+}
+
 // Position is a shared mixin with X and Y
 type Position struct {
 	X int `json:"x"`
@@ -55,6 +85,10 @@ type City struct {
 	BoardID    ID     `json:"boardId"`
 	Name       string `json:"name"`
 	Position   `json:"position"`
+	Version    int    `json:"version"`
+	PlayerRange
+	// Upgrade The ability a player upgrades when they establish a route into the city
+	Upgrade    Ability     `json:"upgrade"`
 	CitySpaces []CitySpace `json:"spaces"`
 }
 
@@ -70,9 +104,14 @@ type CitySpace struct {
 // Route Connects two City on a Board
 type Route struct {
 	Model
+	BoardID     ID           `json:"boardId"`
 	StartCityID ID           `json:"startCityId"`
 	EndCityID   ID           `json:"endCityId"`
 	TavernFlag  bool         `json:"tavernFlag"`
+	// StartingTokenOrder Where the route comes in the order bonus tokens are placed on taverns at setup.
+	// Zero means the route doesn't get a starting token.
+	StartingTokenOrder int `json:"startingTokenOrder"`
+	PlayerRange
 	RouteSpaces []RouteSpace `json:"spaces"`
 }
 
@@ -0,0 +1,91 @@
+package app
+
+import "math"
+
+// ResizeMode How the cities on a board move when the board is resized
+type ResizeMode string
+
+const (
+	// ResizeKeep Cities stay where they are, even if that is now off the board
+	ResizeKeep ResizeMode = "keep"
+	// ResizeScale Cities keep their place relative to the size of the board
+	ResizeScale ResizeMode = "scale"
+	// ResizeAnchor The board grows or shrinks away from the anchor, carrying the cities along with it.
+	// Cities that end up off the board are moved onto its nearest edge.
+	ResizeAnchor ResizeMode = "anchor"
+)
+
+// ResizeAnchorPoint The part of the board that stays put when it is resized with ResizeAnchor
+type ResizeAnchorPoint string
+
+const (
+	AnchorTopLeft     ResizeAnchorPoint = "top-left"
+	AnchorTop         ResizeAnchorPoint = "top"
+	AnchorTopRight    ResizeAnchorPoint = "top-right"
+	AnchorLeft        ResizeAnchorPoint = "left"
+	AnchorCenter      ResizeAnchorPoint = "center"
+	AnchorRight       ResizeAnchorPoint = "right"
+	AnchorBottomLeft  ResizeAnchorPoint = "bottom-left"
+	AnchorBottom      ResizeAnchorPoint = "bottom"
+	AnchorBottomRight ResizeAnchorPoint = "bottom-right"
+)
+
+// anchorFractions How far across and down the board each anchor is
+var anchorFractions = map[ResizeAnchorPoint][2]float64{
+	AnchorTopLeft:     {0, 0},
+	AnchorTop:         {0.5, 0},
+	AnchorTopRight:    {1, 0},
+	AnchorLeft:        {0, 0.5},
+	AnchorCenter:      {0.5, 0.5},
+	AnchorRight:       {1, 0.5},
+	AnchorBottomLeft:  {0, 1},
+	AnchorBottom:      {0.5, 1},
+	AnchorBottomRight: {1, 1},
+}
+
+// validateResize Fill in the defaults for a resize, keeping cities in place and anchoring to the top left
+func validateResize(form *UpdateBoardForm) {
+	switch form.ResizeMode {
+	case "":
+		form.ResizeMode = ResizeKeep
+	case ResizeKeep, ResizeScale, ResizeAnchor:
+	default:
+		form.AddError("ResizeMode", "must be keep, scale, or anchor")
+	}
+
+	if form.ResizeAnchor == "" {
+		form.ResizeAnchor = AnchorTopLeft
+	} else if _, valid := anchorFractions[form.ResizeAnchor]; !valid {
+		form.AddError("ResizeAnchor", "must be top-left, top, top-right, left, center, right, bottom-left, bottom, or bottom-right")
+	}
+}
+
+// resizePosition Where a city at position should be after the board is resized from the old dimensions to the new ones
+func resizePosition(position Position, mode ResizeMode, anchor ResizeAnchorPoint, oldWidth, oldHeight, newWidth, newHeight int) Position {
+	switch mode {
+	case ResizeScale:
+		if oldWidth > 0 {
+			position.X = int(math.Round(float64(position.X) * float64(newWidth) / float64(oldWidth)))
+		}
+		if oldHeight > 0 {
+			position.Y = int(math.Round(float64(position.Y) * float64(newHeight) / float64(oldHeight)))
+		}
+	case ResizeAnchor:
+		fractions := anchorFractions[anchor]
+		position.X += int(math.Round(float64(newWidth-oldWidth) * fractions[0]))
+		position.Y += int(math.Round(float64(newHeight-oldHeight) * fractions[1]))
+		position.X = clampInt(position.X, 0, newWidth)
+		position.Y = clampInt(position.Y, 0, newHeight)
+	}
+	return position
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
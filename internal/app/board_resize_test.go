@@ -0,0 +1,53 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestResizePosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ResizeMode
+		anchor   ResizeAnchorPoint
+		expected Position
+	}{
+		{"keep", ResizeKeep, AnchorTopLeft, Position{X: 100, Y: 50}},
+		{"scale", ResizeScale, AnchorTopLeft, Position{X: 200, Y: 75}},
+		{"anchor top left", ResizeAnchor, AnchorTopLeft, Position{X: 100, Y: 50}},
+		{"anchor center", ResizeAnchor, AnchorCenter, Position{X: 200, Y: 75}},
+		{"anchor bottom right", ResizeAnchor, AnchorBottomRight, Position{X: 300, Y: 100}},
+	}
+
+	for _, test := range tests {
+		// 200x100 to 400x150
+		actual := resizePosition(Position{X: 100, Y: 50}, test.mode, test.anchor, 200, 100, 400, 150)
+		if actual != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, actual)
+		}
+	}
+
+	// Shrinking crops cities onto the edge of the board
+	actual := resizePosition(Position{X: 190, Y: 10}, ResizeAnchor, AnchorBottomRight, 200, 100, 100, 50)
+	assert.New(t).That(actual).IsEqualTo(Position{X: 90, Y: 0})
+
+	// A board with no size can't be scaled from
+	actual = resizePosition(Position{X: 10, Y: 10}, ResizeScale, AnchorTopLeft, 0, 0, 100, 50)
+	assert.New(t).That(actual).IsEqualTo(Position{X: 10, Y: 10})
+}
+
+func TestValidateResize(t *testing.T) {
+	assert := assert.New(t)
+
+	form := UpdateBoardForm{}
+	validateResize(&form)
+	assert.ThatBool(form.HasError()).IsFalse()
+	assert.That(form.ResizeMode).IsEqualTo(ResizeKeep)
+	assert.That(form.ResizeAnchor).IsEqualTo(AnchorTopLeft)
+
+	form = UpdateBoardForm{ResizeMode: "stretch", ResizeAnchor: "middle"}
+	validateResize(&form)
+	assert.ThatInt(len(form.Errors["ResizeMode"])).IsEqualTo(1)
+	assert.ThatInt(len(form.Errors["ResizeAnchor"])).IsEqualTo(1)
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// BoardSort The field boards are listed by
+type BoardSort string
+
+const (
+	BoardSortName    BoardSort = "name"
+	BoardSortCreated BoardSort = "created"
+	BoardSortUpdated BoardSort = "updated"
+)
+
+const (
+	DefaultBoardsPerPage = 20
+	MaxBoardsPerPage     = 100
+)
+
+// BoardQuery Which boards to list and in what order. Boards with the same sort value are listed by ID.
+type BoardQuery struct {
+	// Name Only boards whose name contains this, ignoring case. Empty matches every board.
+	Name       string
+	Sort       BoardSort
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+// BoardSearchForm The query parameters of the board listing, as given.
+// Empty fields fall back to listing the first page of every board by name.
+type BoardSearchForm struct {
+	Form    `json:"-"`
+	Query   string `json:"q"`
+	Sort    string `json:"sort"`
+	Order   string `json:"order"`
+	Page    string `json:"page"`
+	PerPage string `json:"perPage"`
+
+	query   BoardQuery
+	page    int
+	perPage int
+}
+
+func (f *BoardSearchForm) IsValid() bool {
+	f.query = BoardQuery{Name: f.Query, Sort: BoardSortName}
+	f.page = 1
+	f.perPage = DefaultBoardsPerPage
+
+	switch BoardSort(f.Sort) {
+	case "":
+	case BoardSortName, BoardSortCreated, BoardSortUpdated:
+		f.query.Sort = BoardSort(f.Sort)
+	default:
+		f.AddError("Sort", fmt.Sprintf("must be one of %q, %q or %q", BoardSortName, BoardSortCreated, BoardSortUpdated))
+	}
+
+	switch f.Order {
+	case "", "asc":
+	case "desc":
+		f.query.Descending = true
+	default:
+		f.AddError("Order", `must be "asc" or "desc"`)
+	}
+
+	if f.Page != "" {
+		page, err := strconv.Atoi(f.Page)
+		if err != nil || page < 1 {
+			f.AddError("Page", "must be a whole number of at least 1")
+		} else {
+			f.page = page
+		}
+	}
+
+	if f.PerPage != "" {
+		perPage, err := strconv.Atoi(f.PerPage)
+		if err != nil || perPage < 1 || perPage > MaxBoardsPerPage {
+			f.AddError("PerPage", fmt.Sprintf("must be a whole number from 1 to %d", MaxBoardsPerPage))
+		} else {
+			f.perPage = perPage
+		}
+	}
+
+	f.query.Limit = f.perPage
+	f.query.Offset = (f.page - 1) * f.perPage
+
+	return !f.HasError()
+}
+
+// BoardQuery The query described by the form. Only meaningful once the form is valid.
+func (f *BoardSearchForm) BoardQuery() BoardQuery {
+	return f.query
+}
+
+// BoardPage One page of the boards matching a search
+type BoardPage struct {
+	Boards  []Board `json:"boards"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"perPage"`
+	// Total The number of boards matching the search, on every page
+	Total int `json:"total"`
+}
+
+// PageCount The number of pages needed to list every matching board, which is at least one
+func (p BoardPage) PageCount() int {
+	if p.Total == 0 || p.PerPage == 0 {
+		return 1
+	}
+	return (p.Total + p.PerPage - 1) / p.PerPage
+}
+
+func (p BoardPage) HasPrevious() bool {
+	return p.Page > 1
+}
+
+func (p BoardPage) HasNext() bool {
+	return p.Page < p.PageCount()
+}
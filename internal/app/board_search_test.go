@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestBoardSearchFormIsValid(t *testing.T) {
+	assert := assert.New(t)
+
+	form := BoardSearchForm{}
+	assert.ThatBool(form.IsValid()).IsTrue()
+	assert.That(form.BoardQuery()).IsEqualTo(BoardQuery{Sort: BoardSortName, Limit: DefaultBoardsPerPage})
+
+	form = BoardSearchForm{Query: "Hansa", Sort: "updated", Order: "desc", Page: "3", PerPage: "10"}
+	assert.ThatBool(form.IsValid()).IsTrue()
+	assert.That(form.BoardQuery()).IsEqualTo(BoardQuery{
+		Name:       "Hansa",
+		Sort:       BoardSortUpdated,
+		Descending: true,
+		Offset:     20,
+		Limit:      10,
+	})
+
+	form = BoardSearchForm{Sort: "width", Order: "up", Page: "0", PerPage: "1000"}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	assert.ThatInt(len(form.Errors)).IsEqualTo(4)
+}
+
+func TestBoardPage(t *testing.T) {
+	assert := assert.New(t)
+
+	page := BoardPage{Page: 1, PerPage: 20}
+	assert.ThatInt(page.PageCount()).IsEqualTo(1)
+	assert.ThatBool(page.HasPrevious()).IsFalse()
+	assert.ThatBool(page.HasNext()).IsFalse()
+
+	page = BoardPage{Page: 2, PerPage: 20, Total: 41}
+	assert.ThatInt(page.PageCount()).IsEqualTo(3)
+	assert.ThatBool(page.HasPrevious()).IsTrue()
+	assert.ThatBool(page.HasNext()).IsTrue()
+}
+
+func TestSearchBoards(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{
+			{Model: Model{ID: 1}, Name: "Hansa Teutonica"},
+			{Model: Model{ID: 2}, Name: "Britannia"},
+			{Model: Model{ID: 3}, Name: "Hansa East"},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	form := BoardSearchForm{Query: "hansa", PerPage: "1", Page: "2"}
+	page, err := service.SearchBoards(context.Background(), &form)
+	if err != nil {
+		t.Fatalf("SearchBoards returned error: %+v", err)
+	}
+	assert.ThatInt(page.Total).IsEqualTo(2)
+	assert.ThatInt(page.Page).IsEqualTo(2)
+	assert.ThatInt(len(page.Boards)).IsEqualTo(1)
+	assert.That(page.Boards[0].ID).IsEqualTo(ID(3))
+
+	form = BoardSearchForm{Sort: "size"}
+	_, err = service.SearchBoards(context.Background(), &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+	assert.ThatInt(len(form.Errors["Sort"])).IsEqualTo(1)
+}
@@ -0,0 +1,121 @@
+package app
+
+import "sort"
+
+// OfficeCount How many offices on the board are of one type and need one privilege level
+type OfficeCount struct {
+	SpaceType         TradesmanType `json:"spaceType"`
+	RequiredPrivilege int           `json:"requiredPrivilege"`
+	Count             int           `json:"count"`
+}
+
+// DegreeCount How many cities have the same number of routes leading out of them
+type DegreeCount struct {
+	Routes int `json:"routes"`
+	Cities int `json:"cities"`
+}
+
+// BoardStats Design metrics for comparing boards, as computed by NewBoardStats
+type BoardStats struct {
+	BoardID     ID  `json:"boardId"`
+	Cities      int `json:"cities"`
+	Offices     int `json:"offices"`
+	Routes      int `json:"routes"`
+	RouteSpaces int `json:"routeSpaces"`
+	// OfficesByType Ordered by space type, then required privilege
+	OfficesByType []OfficeCount `json:"officesByType"`
+	// AverageRouteLength The mean number of spaces per route, or zero if there are no routes
+	AverageRouteLength float64 `json:"averageRouteLength"`
+	// DegreeDistribution Ordered by number of routes, leaving out numbers no city has
+	DegreeDistribution []DegreeCount `json:"degreeDistribution"`
+	// Diameter The most routes that must be travelled to get from one city to another, over every pair of
+	// cities that are connected at all
+	Diameter int `json:"diameter"`
+}
+
+// NewBoardStats Count up the metrics of a full board (as loaded by BoardCrudRepository.GetBoardGraphByID)
+func NewBoardStats(board *Board) BoardStats {
+	stats := BoardStats{
+		BoardID:            board.ID,
+		Cities:             len(board.Cities),
+		Routes:             len(board.Routes),
+		OfficesByType:      make([]OfficeCount, 0),
+		DegreeDistribution: make([]DegreeCount, 0),
+	}
+
+	type officeKey struct {
+		spaceType TradesmanType
+		privilege int
+	}
+	offices := make(map[officeKey]int)
+	for _, city := range board.Cities {
+		for _, space := range city.CitySpaces {
+			offices[officeKey{space.SpaceType, space.RequiredPrivilege}]++
+			stats.Offices++
+		}
+	}
+	for key, count := range offices {
+		stats.OfficesByType = append(stats.OfficesByType, OfficeCount{
+			SpaceType:         key.spaceType,
+			RequiredPrivilege: key.privilege,
+			Count:             count,
+		})
+	}
+	sort.Slice(stats.OfficesByType, func(i, j int) bool {
+		a, b := stats.OfficesByType[i], stats.OfficesByType[j]
+		if a.SpaceType != b.SpaceType {
+			return a.SpaceType < b.SpaceType
+		}
+		return a.RequiredPrivilege < b.RequiredPrivilege
+	})
+
+	neighbors := make(map[ID][]ID, len(board.Cities))
+	for _, route := range board.Routes {
+		stats.RouteSpaces += len(route.RouteSpaces)
+		neighbors[route.StartCityID] = append(neighbors[route.StartCityID], route.EndCityID)
+		neighbors[route.EndCityID] = append(neighbors[route.EndCityID], route.StartCityID)
+	}
+	if stats.Routes > 0 {
+		stats.AverageRouteLength = float64(stats.RouteSpaces) / float64(stats.Routes)
+	}
+
+	degrees := make(map[int]int)
+	for _, city := range board.Cities {
+		degrees[len(neighbors[city.ID])]++
+	}
+	for routes, cities := range degrees {
+		stats.DegreeDistribution = append(stats.DegreeDistribution, DegreeCount{Routes: routes, Cities: cities})
+	}
+	sort.Slice(stats.DegreeDistribution, func(i, j int) bool {
+		return stats.DegreeDistribution[i].Routes < stats.DegreeDistribution[j].Routes
+	})
+
+	for _, city := range board.Cities {
+		if farthest := farthestDistance(city.ID, neighbors); farthest > stats.Diameter {
+			stats.Diameter = farthest
+		}
+	}
+
+	return stats
+}
+
+// farthestDistance The most routes between the city and any other city it is connected to
+func farthestDistance(from ID, neighbors map[ID][]ID) int {
+	distances := map[ID]int{from: 0}
+	queue := []ID{from}
+	farthest := 0
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range neighbors[current] {
+			if _, visited := distances[next]; !visited {
+				distances[next] = distances[current] + 1
+				if distances[next] > farthest {
+					farthest = distances[next]
+				}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return farthest
+}
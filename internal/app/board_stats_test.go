@@ -0,0 +1,62 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestNewBoardStats(t *testing.T) {
+	assert := assert.New(t)
+
+	// A line of three cities (1 - 2 - 3), a pair off to the side (4 - 5), and a city with no routes (6)
+	board := Board{
+		Model: Model{ID: 9},
+		Cities: []City{
+			{Model: Model{ID: 1}, CitySpaces: []CitySpace{
+				{SpaceType: TraderID, RequiredPrivilege: 1},
+				{SpaceType: MerchantID, RequiredPrivilege: 2},
+			}},
+			{Model: Model{ID: 2}, CitySpaces: []CitySpace{
+				{SpaceType: TraderID, RequiredPrivilege: 1},
+			}},
+			{Model: Model{ID: 3}},
+			{Model: Model{ID: 4}},
+			{Model: Model{ID: 5}},
+			{Model: Model{ID: 6}},
+		},
+		Routes: []Route{
+			{StartCityID: 1, EndCityID: 2, RouteSpaces: make([]RouteSpace, 3)},
+			{StartCityID: 2, EndCityID: 3, RouteSpaces: make([]RouteSpace, 2)},
+			{StartCityID: 4, EndCityID: 5, RouteSpaces: make([]RouteSpace, 2)},
+		},
+	}
+
+	stats := NewBoardStats(&board)
+	assert.That(stats.BoardID).IsEqualTo(ID(9))
+	assert.ThatInt(stats.Cities).IsEqualTo(6)
+	assert.ThatInt(stats.Offices).IsEqualTo(3)
+	assert.That(stats.OfficesByType).IsEqualTo([]OfficeCount{
+		{SpaceType: TraderID, RequiredPrivilege: 1, Count: 2},
+		{SpaceType: MerchantID, RequiredPrivilege: 2, Count: 1},
+	})
+	assert.ThatInt(stats.Routes).IsEqualTo(3)
+	assert.ThatInt(stats.RouteSpaces).IsEqualTo(7)
+	assert.That(stats.AverageRouteLength).IsEqualTo(7.0 / 3.0)
+	assert.That(stats.DegreeDistribution).IsEqualTo([]DegreeCount{
+		{Routes: 0, Cities: 1},
+		{Routes: 1, Cities: 4},
+		{Routes: 2, Cities: 1},
+	})
+	assert.ThatInt(stats.Diameter).IsEqualTo(2)
+}
+
+func TestNewBoardStats_empty(t *testing.T) {
+	assert := assert.New(t)
+
+	stats := NewBoardStats(&Board{})
+	assert.ThatInt(stats.Cities).IsEqualTo(0)
+	assert.That(stats.AverageRouteLength).IsEqualTo(0.0)
+	assert.ThatInt(len(stats.DegreeDistribution)).IsEqualTo(0)
+	assert.ThatInt(stats.Diameter).IsEqualTo(0)
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FindingCode identifies the kind of problem found by BoardValidator
+type FindingCode string
+
+const (
+	FindingNoCities              FindingCode = "noCities"
+	FindingDisconnectedCities    FindingCode = "disconnectedCities"
+	FindingCityWithoutOffices    FindingCode = "cityWithoutOffices"
+	FindingRouteWithoutSpaces    FindingCode = "routeWithoutSpaces"
+	FindingNonMonotonicPrivilege FindingCode = "nonMonotonicPrivilege"
+	FindingCityOutOfBounds       FindingCode = "cityOutOfBounds"
+	FindingDuplicateCityName     FindingCode = "duplicateCityName"
+	FindingDuplicateUpgrade      FindingCode = "duplicateUpgrade"
+)
+
+// Finding A single problem that keeps a board from being playable, along with the parts of the board involved
+type Finding struct {
+	Code     FindingCode `json:"code"`
+	Message  string      `json:"message"`
+	CityIDs  []ID        `json:"cityIds,omitempty"`
+	RouteIDs []ID        `json:"routeIds,omitempty"`
+}
+
+// BoardValidationReport Result of validating a board. A board is ready to play when there are no findings.
+type BoardValidationReport struct {
+	BoardID  ID        `json:"boardId"`
+	Valid    bool      `json:"valid"`
+	Findings []Finding `json:"findings"`
+}
+
+func (r *BoardValidationReport) add(finding Finding) {
+	r.Findings = append(r.Findings, finding)
+	r.Valid = false
+}
+
+// BoardValidator Inspects a full board (as loaded by BoardCrudRepository.GetBoardGraphByID)
+// for problems that would make it unplayable.
+type BoardValidator struct{}
+
+func NewBoardValidator() BoardValidator {
+	return BoardValidator{}
+}
+
+func (v BoardValidator) Validate(board *Board) BoardValidationReport {
+	report := BoardValidationReport{
+		BoardID:  board.ID,
+		Valid:    true,
+		Findings: make([]Finding, 0),
+	}
+
+	if len(board.Cities) == 0 {
+		report.add(Finding{
+			Code:    FindingNoCities,
+			Message: "board has no cities",
+		})
+		return report
+	}
+
+	v.checkConnectivity(board, &report)
+	v.checkDuplicateCityNames(board, &report)
+	v.checkDuplicateUpgrades(board, &report)
+
+	for _, city := range board.Cities {
+		v.checkCity(board, &city, &report)
+	}
+
+	for _, route := range board.Routes {
+		if len(route.RouteSpaces) == 0 {
+			report.add(Finding{
+				Code:     FindingRouteWithoutSpaces,
+				Message:  fmt.Sprintf("route %d has no spaces", route.ID),
+				CityIDs:  []ID{route.StartCityID, route.EndCityID},
+				RouteIDs: []ID{route.ID},
+			})
+		}
+	}
+
+	return report
+}
+
+func (v BoardValidator) checkCity(board *Board, city *City, report *BoardValidationReport) {
+	if len(city.CitySpaces) == 0 {
+		report.add(Finding{
+			Code:    FindingCityWithoutOffices,
+			Message: fmt.Sprintf("%s has no offices", city.Name),
+			CityIDs: []ID{city.ID},
+		})
+	}
+
+	spaces := make([]CitySpace, len(city.CitySpaces))
+	copy(spaces, city.CitySpaces)
+	sort.SliceStable(spaces, func(i, j int) bool {
+		return spaces[i].Order < spaces[j].Order
+	})
+	for i := 1; i < len(spaces); i++ {
+		if spaces[i].RequiredPrivilege < spaces[i-1].RequiredPrivilege {
+			report.add(Finding{
+				Code: FindingNonMonotonicPrivilege,
+				Message: fmt.Sprintf(
+					"%s office %d requires privilege %d, which is less than the %d required by the office before it",
+					city.Name, spaces[i].Order, spaces[i].RequiredPrivilege, spaces[i-1].RequiredPrivilege),
+				CityIDs: []ID{city.ID},
+			})
+			break
+		}
+	}
+
+	if city.Position.X < 0 || city.Position.Y < 0 || city.Position.X > board.Width || city.Position.Y > board.Height {
+		report.add(Finding{
+			Code: FindingCityOutOfBounds,
+			Message: fmt.Sprintf("%s at (%d, %d) is outside the %dx%d board",
+				city.Name, city.Position.X, city.Position.Y, board.Width, board.Height),
+			CityIDs: []ID{city.ID},
+		})
+	}
+}
+
+// checkConnectivity reports every city that can't be reached from the largest connected group of cities
+func (v BoardValidator) checkConnectivity(board *Board, report *BoardValidationReport) {
+	neighbors := make(map[ID][]ID, len(board.Cities))
+	for _, route := range board.Routes {
+		neighbors[route.StartCityID] = append(neighbors[route.StartCityID], route.EndCityID)
+		neighbors[route.EndCityID] = append(neighbors[route.EndCityID], route.StartCityID)
+	}
+
+	visited := make(map[ID]bool, len(board.Cities))
+	var components [][]ID
+	for _, city := range board.Cities {
+		if visited[city.ID] {
+			continue
+		}
+
+		var component []ID
+		queue := []ID{city.ID}
+		visited[city.ID] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			component = append(component, current)
+			for _, next := range neighbors[current] {
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		components = append(components, component)
+	}
+
+	if len(components) <= 1 {
+		return
+	}
+
+	largest := 0
+	for i, component := range components {
+		if len(component) > len(components[largest]) {
+			largest = i
+		}
+	}
+
+	var disconnected []ID
+	for i, component := range components {
+		if i != largest {
+			disconnected = append(disconnected, component...)
+		}
+	}
+
+	report.add(Finding{
+		Code: FindingDisconnectedCities,
+		Message: fmt.Sprintf("cities form %d separate groups; %d cities are not connected to the rest of the board",
+			len(components), len(disconnected)),
+		CityIDs: disconnected,
+	})
+}
+
+func (v BoardValidator) checkDuplicateCityNames(board *Board, report *BoardValidationReport) {
+	byName := make(map[string][]ID, len(board.Cities))
+	var names []string
+	for _, city := range board.Cities {
+		key := strings.ToLower(strings.TrimSpace(city.Name))
+		if _, exists := byName[key]; !exists {
+			names = append(names, key)
+		}
+		byName[key] = append(byName[key], city.ID)
+	}
+
+	for _, name := range names {
+		ids := byName[name]
+		if len(ids) > 1 {
+			report.add(Finding{
+				Code:    FindingDuplicateCityName,
+				Message: fmt.Sprintf("%d cities are named %q", len(ids), name),
+				CityIDs: ids,
+			})
+		}
+	}
+}
+
+// checkDuplicateUpgrades reports abilities that more than one city upgrades, since each ability belongs to a single city
+func (v BoardValidator) checkDuplicateUpgrades(board *Board, report *BoardValidationReport) {
+	byAbility := make(map[Ability][]ID, len(Abilities))
+	for _, city := range board.Cities {
+		if city.Upgrade != AbilityNone {
+			byAbility[city.Upgrade] = append(byAbility[city.Upgrade], city.ID)
+		}
+	}
+
+	for _, ability := range Abilities {
+		ids := byAbility[ability]
+		if len(ids) > 1 {
+			report.add(Finding{
+				Code:    FindingDuplicateUpgrade,
+				Message: fmt.Sprintf("%d cities upgrade the %s ability", len(ids), ability),
+				CityIDs: ids,
+			})
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"github.com/assertgo/assert"
+	"testing"
+)
+
+func newPlayableTestBoard() *Board {
+	return &Board{
+		Model:  Model{ID: 1},
+		Name:   "Board 1",
+		Width:  100,
+		Height: 100,
+		Cities: []City{
+			{
+				Model:    Model{ID: 1},
+				BoardID:  1,
+				Name:     "City 1",
+				Position: Position{X: 10, Y: 10},
+				CitySpaces: []CitySpace{
+					{Model: Model{ID: 1}, CityID: 1, Order: 1, SpaceType: TraderID, RequiredPrivilege: 1},
+					{Model: Model{ID: 2}, CityID: 1, Order: 2, SpaceType: MerchantID, RequiredPrivilege: 2},
+				},
+			},
+			{
+				Model:    Model{ID: 2},
+				BoardID:  1,
+				Name:     "City 2",
+				Position: Position{X: 50, Y: 50},
+				CitySpaces: []CitySpace{
+					{Model: Model{ID: 3}, CityID: 2, Order: 1, SpaceType: TraderID, RequiredPrivilege: 1},
+				},
+			},
+		},
+		Routes: []Route{
+			{
+				Model:       Model{ID: 1},
+				BoardID:     1,
+				StartCityID: 1,
+				EndCityID:   2,
+				RouteSpaces: []RouteSpace{
+					{Model: Model{ID: 1}, RouteID: 1, Order: 1},
+				},
+			},
+		},
+	}
+}
+
+func findingCodes(report BoardValidationReport) []FindingCode {
+	codes := make([]FindingCode, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+func TestBoardValidatorAcceptsPlayableBoard(t *testing.T) {
+	report := NewBoardValidator().Validate(newPlayableTestBoard())
+
+	assert := assert.New(t)
+	assert.ThatBool(report.Valid).IsTrue()
+	assert.ThatInt(len(report.Findings)).IsEqualTo(0)
+}
+
+func TestBoardValidatorFindings(t *testing.T) {
+	cases := []struct {
+		name     string
+		mutate   func(board *Board)
+		expected FindingCode
+		cityIDs  []ID
+	}{
+		{
+			name: "no cities",
+			mutate: func(board *Board) {
+				board.Cities = nil
+				board.Routes = nil
+			},
+			expected: FindingNoCities,
+		},
+		{
+			name: "disconnected city",
+			mutate: func(board *Board) {
+				board.Cities = append(board.Cities, City{
+					Model:      Model{ID: 3},
+					Name:       "City 3",
+					CitySpaces: []CitySpace{{CityID: 3, Order: 1, RequiredPrivilege: 1}},
+				})
+			},
+			expected: FindingDisconnectedCities,
+			cityIDs:  []ID{3},
+		},
+		{
+			name: "city without offices",
+			mutate: func(board *Board) {
+				board.Cities[1].CitySpaces = nil
+			},
+			expected: FindingCityWithoutOffices,
+			cityIDs:  []ID{2},
+		},
+		{
+			name: "route without spaces",
+			mutate: func(board *Board) {
+				board.Routes[0].RouteSpaces = nil
+			},
+			expected: FindingRouteWithoutSpaces,
+		},
+		{
+			name: "privilege decreases",
+			mutate: func(board *Board) {
+				board.Cities[0].CitySpaces[1].RequiredPrivilege = 0
+			},
+			expected: FindingNonMonotonicPrivilege,
+			cityIDs:  []ID{1},
+		},
+		{
+			name: "city out of bounds",
+			mutate: func(board *Board) {
+				board.Cities[1].Position.X = 101
+			},
+			expected: FindingCityOutOfBounds,
+			cityIDs:  []ID{2},
+		},
+		{
+			name: "duplicate city names",
+			mutate: func(board *Board) {
+				board.Cities[1].Name = " city 1"
+			},
+			expected: FindingDuplicateCityName,
+			cityIDs:  []ID{1, 2},
+		},
+		{
+			name: "duplicate upgrades",
+			mutate: func(board *Board) {
+				board.Cities[0].Upgrade = AbilityBank
+				board.Cities[1].Upgrade = AbilityBank
+			},
+			expected: FindingDuplicateUpgrade,
+			cityIDs:  []ID{1, 2},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			board := newPlayableTestBoard()
+			tc.mutate(board)
+
+			report := NewBoardValidator().Validate(board)
+			if report.Valid {
+				t.Fatal("report should not be valid")
+			}
+
+			for _, finding := range report.Findings {
+				if finding.Code != tc.expected {
+					continue
+				}
+				if tc.cityIDs != nil {
+					assert.New(t).That(finding.CityIDs).IsEqualTo(tc.cityIDs)
+				}
+				return
+			}
+			t.Errorf("expected finding %s, got %v", tc.expected, findingCodes(report))
+		})
+	}
+}
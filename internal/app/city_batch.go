@@ -0,0 +1,84 @@
+package app
+
+import "fmt"
+
+// CityBatchOp The change a CityBatchOperation makes
+type CityBatchOp string
+
+const (
+	CityBatchCreate CityBatchOp = "create"
+	CityBatchUpdate CityBatchOp = "update"
+	CityBatchDelete CityBatchOp = "delete"
+)
+
+// CityBatchOperation One change to a city within a CityBatchForm.
+// ID is the city's ID or, for a city created earlier in the same batch, the temporary negative ID the
+// board editor gave it. Cities to create must be given a temporary ID. Version is optional, and is
+// checked like If-Match when updating a city.
+type CityBatchOperation struct {
+	Op      CityBatchOp `json:"op"`
+	ID      int64       `json:"id"`
+	City    CityForm    `json:"city"`
+	Version int         `json:"version"`
+}
+
+// CityBatchForm Changes to many cities on a board, which are all made or none of them are
+type CityBatchForm struct {
+	Form       `json:"-"`
+	Operations []CityBatchOperation `json:"operations"`
+}
+
+// CityBatchResult Cities created or updated by a batch, as they are after all of its operations,
+// along with the real IDs given to the cities with temporary IDs
+type CityBatchResult struct {
+	IDs     map[int64]ID `json:"ids"`
+	Cities  []City       `json:"cities"`
+	Deleted []ID         `json:"deleted"`
+}
+
+// IsValid Check every operation before any is applied. Temporary IDs must be created before they are used.
+func (f *CityBatchForm) IsValid() bool {
+	if len(f.Operations) == 0 {
+		f.AddError("Operations", "must not be empty")
+	}
+
+	tempIDs := make(map[int64]bool)
+	for i := range f.Operations {
+		operation := &f.Operations[i]
+		field := fmt.Sprintf("Operations[%d]", i)
+
+		switch operation.Op {
+		case CityBatchCreate:
+			if operation.ID >= 0 {
+				f.AddError(field, "new cities must be given a temporary negative ID")
+			} else if tempIDs[operation.ID] {
+				f.AddError(field, fmt.Sprintf("temporary ID %d is used by another new city", operation.ID))
+			}
+			tempIDs[operation.ID] = true
+			validatePlayerRange(&operation.City.Form, operation.City.PlayerRangeForm.Apply(PlayerRange{}))
+			if operation.City.HasError() {
+				f.AddError(field, "city has an invalid player range")
+			}
+		case CityBatchUpdate, CityBatchDelete:
+			if operation.ID == 0 {
+				f.AddError(field, "must have an ID")
+			} else if operation.ID < 0 && !tempIDs[operation.ID] {
+				f.AddError(field, fmt.Sprintf("temporary ID %d does not belong to a city created earlier in the batch", operation.ID))
+			}
+		default:
+			f.AddError(field, "op must be create, update, or delete")
+		}
+
+		if operation.Op != CityBatchDelete {
+			operation.City.NormalizeInputs()
+			if !operation.City.IsValid() {
+				f.AddError(field, "city is invalid")
+			}
+			if operation.City.Upgrade != nil && !operation.City.Upgrade.IsValid() {
+				f.AddError(field, fmt.Sprintf("upgrade %q is not an ability", *operation.City.Upgrade))
+			}
+		}
+	}
+
+	return !f.HasError()
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestCityBatchForm_IsValid(t *testing.T) {
+	assert := assert.New(t)
+
+	form := CityBatchForm{
+		Operations: []CityBatchOperation{
+			{Op: CityBatchCreate, ID: -1, City: CityForm{Name: "  Lübeck "}},
+			{Op: CityBatchUpdate, ID: -1, City: CityForm{Name: "Lübeck", Position: Position{X: 10, Y: 20}}},
+			{Op: CityBatchUpdate, ID: 7, City: CityForm{Name: "Hamburg"}},
+			{Op: CityBatchDelete, ID: 8},
+		},
+	}
+	assert.ThatBool(form.IsValid()).IsTrue()
+	assert.ThatString(form.Operations[0].City.Name).IsEqualTo("Lübeck")
+
+	form = CityBatchForm{}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	assert.ThatInt(len(form.Errors["Operations"])).IsEqualTo(1)
+
+	form = CityBatchForm{
+		Operations: []CityBatchOperation{
+			{Op: CityBatchCreate, ID: 3},
+			{Op: CityBatchCreate, ID: -1},
+			{Op: CityBatchCreate, ID: -1},
+			{Op: CityBatchUpdate, ID: -2},
+			{Op: CityBatchDelete},
+			{Op: "move", ID: 4},
+		},
+	}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	for _, field := range []string{"Operations[0]", "Operations[2]", "Operations[3]", "Operations[4]", "Operations[5]"} {
+		if len(form.Errors[field]) != 1 {
+			t.Errorf("expected an error on %s, got %+v", field, form.Errors)
+		}
+	}
+	assert.ThatInt(len(form.Errors["Operations[1]"])).IsEqualTo(0)
+}
@@ -0,0 +1,85 @@
+package app
+
+import "fmt"
+
+// Sizes of the parts of a city as drawn on the board
+const (
+	CityOfficeSize = 20
+	CityOfficeGap  = 4
+	CityPadding    = 6
+)
+
+// CityFootprint The size of the box a city takes up on the board, which is centered on its position.
+// Offices are laid out in a row, and a city without any is as wide as one with a single office.
+func CityFootprint(city *City) (width, height int) {
+	offices := len(city.CitySpaces)
+	if offices == 0 {
+		offices = 1
+	}
+	width = offices*CityOfficeSize + (offices-1)*CityOfficeGap + 2*CityPadding
+	height = CityOfficeSize + 2*CityPadding
+	return width, height
+}
+
+type CityWarningKind string
+
+const (
+	// CityWarningOverlap The city's footprint overlaps that of OtherCityID
+	CityWarningOverlap CityWarningKind = "overlap"
+	// CityWarningOutOfBounds Some or all of the city's footprint is off the board
+	CityWarningOutOfBounds CityWarningKind = "outOfBounds"
+)
+
+// CityWarning Something about where a city was placed that is allowed, but probably not what the user wants
+type CityWarning struct {
+	Kind        CityWarningKind `json:"kind"`
+	Message     string          `json:"message"`
+	OtherCityID ID              `json:"otherCityId,omitempty"`
+}
+
+type cityRect struct {
+	left, top, right, bottom int
+}
+
+func newCityRect(city *City) cityRect {
+	width, height := CityFootprint(city)
+	left := city.Position.X - width/2
+	top := city.Position.Y - height/2
+	return cityRect{left: left, top: top, right: left + width, bottom: top + height}
+}
+
+func (r cityRect) overlaps(other cityRect) bool {
+	return r.left < other.right && other.left < r.right && r.top < other.bottom && other.top < r.bottom
+}
+
+// checkCityGeometry Warn if the city overlaps any other city on the board, or isn't entirely on the board.
+// Boards without a size yet aren't checked for bounds.
+func checkCityGeometry(city *City, board *Board, cities []City) []CityWarning {
+	warnings := []CityWarning{}
+	rect := newCityRect(city)
+
+	if board.Width > 0 && board.Height > 0 {
+		if rect.left < 0 || rect.top < 0 || rect.right > board.Width || rect.bottom > board.Height {
+			warnings = append(warnings, CityWarning{
+				Kind:    CityWarningOutOfBounds,
+				Message: fmt.Sprintf("%s is not entirely on the %dx%d board", city.Name, board.Width, board.Height),
+			})
+		}
+	}
+
+	for i := range cities {
+		other := &cities[i]
+		if other.ID == city.ID {
+			continue
+		}
+		if rect.overlaps(newCityRect(other)) {
+			warnings = append(warnings, CityWarning{
+				Kind:        CityWarningOverlap,
+				Message:     fmt.Sprintf("%s overlaps %s", city.Name, other.Name),
+				OtherCityID: other.ID,
+			})
+		}
+	}
+
+	return warnings
+}
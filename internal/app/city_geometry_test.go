@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestCityFootprint(t *testing.T) {
+	assert := assert.New(t)
+
+	width, height := CityFootprint(&City{})
+	assert.ThatInt(width).IsEqualTo(32)
+	assert.ThatInt(height).IsEqualTo(32)
+
+	width, _ = CityFootprint(&City{CitySpaces: make([]CitySpace, 3)})
+	assert.ThatInt(width).IsEqualTo(80)
+}
+
+func TestCheckCityGeometry(t *testing.T) {
+	board := Board{Width: 200, Height: 100}
+	cities := []City{
+		{Model: Model{ID: 1}, Name: "Lübeck", Position: Position{X: 50, Y: 50}},
+		{Model: Model{ID: 2}, Name: "Hamburg", Position: Position{X: 70, Y: 60}},
+		{Model: Model{ID: 3}, Name: "Bremen", Position: Position{X: 160, Y: 50}, CitySpaces: make([]CitySpace, 2)},
+		{Model: Model{ID: 4}, Name: "Kiel", Position: Position{X: 195, Y: 50}},
+	}
+	assert := assert.New(t)
+
+	warnings := checkCityGeometry(&cities[0], &board, cities)
+	assert.ThatInt(len(warnings)).IsEqualTo(1)
+	assert.That(warnings[0].Kind).IsEqualTo(CityWarningOverlap)
+	assert.That(warnings[0].OtherCityID).IsEqualTo(ID(2))
+
+	// Bremen is wide enough with two offices to reach Kiel, which hangs off the right edge
+	warnings = checkCityGeometry(&cities[3], &board, cities)
+	assert.ThatInt(len(warnings)).IsEqualTo(2)
+	assert.That(warnings[0].Kind).IsEqualTo(CityWarningOutOfBounds)
+	assert.That(warnings[1].OtherCityID).IsEqualTo(ID(3))
+
+	// Boards without a size don't have bounds
+	warnings = checkCityGeometry(&cities[3], &Board{}, cities[3:])
+	assert.ThatInt(len(warnings)).IsEqualTo(0)
+}
+
+func TestUpdateCityReturnsWarnings(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}, Width: 100, Height: 100}},
+		SingletonCityResult: &City{
+			Model:   Model{ID: 3},
+			BoardID: 1,
+			Name:    "Lübeck",
+		},
+		MultipleCityResult: []City{
+			{Model: Model{ID: 3}, BoardID: 1, Name: "Lübeck"},
+			{Model: Model{ID: 4}, BoardID: 1, Name: "Hamburg", Position: Position{X: 50, Y: 50}},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	form := CityForm{Name: "Lübeck", Position: Position{X: 50, Y: 55}}
+	if _, err := service.UpdateCity(context.Background(), "3", &form); err != nil {
+		t.Fatalf("UpdateCity returned error: %+v", err)
+	}
+	assert.ThatInt(len(form.Warnings)).IsEqualTo(1)
+	assert.That(form.Warnings[0].OtherCityID).IsEqualTo(ID(4))
+
+	form = CityForm{Name: "Lübeck", Position: Position{X: 84, Y: 84}}
+	if _, err := service.UpdateCity(context.Background(), "3", &form); err != nil {
+		t.Fatalf("UpdateCity returned error: %+v", err)
+	}
+	assert.ThatInt(len(form.Warnings)).IsEqualTo(0)
+}
@@ -0,0 +1,87 @@
+package app
+
+import "fmt"
+
+// MaxConnectionAwards The most players that can be awarded for completing a connection objective
+const MaxConnectionAwards = 5
+
+// ConnectionObjective A bonus for the first players to link two cities with their routes
+// (the east-west connection in the original game)
+type ConnectionObjective struct {
+	StartCityID ID `json:"startCityId"`
+	EndCityID   ID `json:"endCityId"`
+	// Awards The prestige points awarded to the first player to link the cities, then the second, and so on
+	Awards []int `json:"awards"`
+}
+
+// Equal Whether both objectives link the same cities for the same awards. Either may be nil.
+func (o *ConnectionObjective) Equal(other *ConnectionObjective) bool {
+	if o == nil || other == nil {
+		return o == other
+	}
+	if o.StartCityID != other.StartCityID || o.EndCityID != other.EndCityID || len(o.Awards) != len(other.Awards) {
+		return false
+	}
+	for i := range o.Awards {
+		if o.Awards[i] != other.Awards[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy A copy that doesn't share its awards with the original
+func (o *ConnectionObjective) Copy() *ConnectionObjective {
+	if o == nil {
+		return nil
+	}
+	copied := *o
+	copied.Awards = make([]int, len(o.Awards))
+	copy(copied.Awards, o.Awards)
+	return &copied
+}
+
+type ConnectionObjectiveForm struct {
+	Form
+	StartCityID ID    `json:"startCityId"`
+	EndCityID   ID    `json:"endCityId"`
+	Awards      []int `json:"awards"`
+}
+
+func (f *ConnectionObjectiveForm) IsValid() bool {
+	if f.StartCityID == 0 {
+		f.AddError("StartCityID", "is required")
+	}
+
+	if f.EndCityID == 0 {
+		f.AddError("EndCityID", "is required")
+	}
+
+	if f.StartCityID != 0 && f.StartCityID == f.EndCityID {
+		f.AddError("EndCityID", "must not be the same as the start city")
+	}
+
+	if len(f.Awards) == 0 {
+		f.AddError("Awards", "must not be empty")
+	} else if len(f.Awards) > MaxConnectionAwards {
+		f.AddError("Awards", fmt.Sprintf("must not have more than %d awards", MaxConnectionAwards))
+	}
+	for _, award := range f.Awards {
+		if award < 0 {
+			f.AddError("Awards", "must not be negative")
+			break
+		}
+	}
+
+	return !f.HasError()
+}
+
+// ConnectionObjective The objective described by the form
+func (f *ConnectionObjectiveForm) ConnectionObjective() *ConnectionObjective {
+	objective := ConnectionObjective{
+		StartCityID: f.StartCityID,
+		EndCityID:   f.EndCityID,
+		Awards:      f.Awards,
+	}
+	return objective.Copy()
+}
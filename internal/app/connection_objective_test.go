@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestConnectionObjectiveFormIsValid(t *testing.T) {
+	assert := assert.New(t)
+
+	form := ConnectionObjectiveForm{StartCityID: 1, EndCityID: 2, Awards: []int{7, 4, 2}}
+	assert.ThatBool(form.IsValid()).IsTrue()
+
+	form = ConnectionObjectiveForm{StartCityID: 1, EndCityID: 1, Awards: []int{-1}}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	assert.ThatInt(len(form.Errors["EndCityID"])).IsEqualTo(1)
+	assert.ThatInt(len(form.Errors["Awards"])).IsEqualTo(1)
+
+	form = ConnectionObjectiveForm{StartCityID: 1, EndCityID: 2, Awards: make([]int, MaxConnectionAwards+1)}
+	assert.ThatBool(form.IsValid()).IsFalse()
+}
+
+func TestConnectionObjectiveEqual(t *testing.T) {
+	assert := assert.New(t)
+	var none *ConnectionObjective
+	objective := &ConnectionObjective{StartCityID: 1, EndCityID: 2, Awards: []int{7, 4}}
+
+	assert.ThatBool(none.Equal(nil)).IsTrue()
+	assert.ThatBool(none.Equal(objective)).IsFalse()
+	assert.ThatBool(objective.Equal(objective.Copy())).IsTrue()
+	assert.ThatBool(objective.Equal(&ConnectionObjective{StartCityID: 1, EndCityID: 2, Awards: []int{7, 3}})).IsFalse()
+}
+
+func TestUpdateConnectionObjective(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}}},
+		Cities: []City{
+			{Model: Model{ID: 3}, BoardID: 1, Name: "Stendal"},
+			{Model: Model{ID: 4}, BoardID: 1, Name: "Arnheim"},
+			{Model: Model{ID: 5}, BoardID: 2, Name: "Elsewhere"},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	form := ConnectionObjectiveForm{StartCityID: 3, EndCityID: 4, Awards: []int{7, 4, 2}}
+	board, err := service.UpdateConnectionObjective(context.Background(), "1", &form)
+	if err != nil {
+		t.Fatalf("UpdateConnectionObjective returned error: %+v", err)
+	}
+	assert.That(board.ConnectionObjective.EndCityID).IsEqualTo(ID(4))
+	assert.ThatInt(len(board.ConnectionObjective.Awards)).IsEqualTo(3)
+	assert.ThatInt(len(repo.BoardCommands)).IsEqualTo(1)
+
+	form = ConnectionObjectiveForm{StartCityID: 3, EndCityID: 5, Awards: []int{7}}
+	_, err = service.UpdateConnectionObjective(context.Background(), "1", &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+	assert.ThatInt(len(form.Errors["EndCityID"])).IsEqualTo(1)
+
+	board, err = service.DeleteConnectionObjective(context.Background(), "1")
+	assert.That(err).IsNil()
+	assert.ThatBool(board.ConnectionObjective == nil).IsTrue()
+}
@@ -12,6 +12,15 @@ var ErrInvalidForm = errors.New("invalid form (check form object errors)")
 // or update a board with a duplicate name
 var ErrNameTaken = errors.New("name already taken")
 
+// ErrNothingToUndo Error to be returned by BoardEditorService.Undo when every command on the board has been undone
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo Error to be returned by BoardEditorService.Redo when no command on the board has been undone
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// ErrUnsupportedPlayerCount Error to be returned when a board is set up for a number of players it can't be played with
+var ErrUnsupportedPlayerCount = errors.New("the board can't be played with that number of players")
+
 type RecordNotFound struct {
 	Name string
 	ID ID
@@ -40,6 +49,49 @@ func NewRecordNotFoundError(name string, id ID) error {
 	}
 }
 
+// StaleRecord Error to be returned when a record was changed by someone else after the caller loaded it
+type StaleRecord struct {
+	Name string
+	ID ID
+}
+
+func (e StaleRecord) Error() string {
+	return fmt.Sprint(e.Name, " with id ", e.ID, " has been changed since it was loaded")
+}
+
+func (e StaleRecord) Is(target error) bool {
+	_, sameType := target.(*StaleRecord)
+	return sameType
+}
+
+func NewStaleRecordError(name string, id ID) error {
+	return &StaleRecord{
+		Name: name,
+		ID: id,
+	}
+}
+
+// BoardInUse Error to be returned by BoardCrudRepository upon attempt to delete a board that games are played on
+type BoardInUse struct {
+	ID    ID
+	Games int
+}
+
+func (e BoardInUse) Error() string {
+	return fmt.Sprint("Board with id ", e.ID, " can't be deleted while ", e.Games, " game(s) are played on it")
+}
+
+func (e BoardInUse) Is(target error) bool {
+	_, sameType := target.(*BoardInUse)
+	return sameType
+}
+
+func NewBoardInUseError(id ID, games int) error {
+	return &BoardInUse{
+		ID:    id,
+		Games: games,
+	}
+}
 
 type ErrInvalidIDString struct {
 	Msg string
@@ -32,11 +32,18 @@ type PlayerBoard struct {
 // Game represents the game state
 type Game struct {
 	Model
-	Name             string `json:"name"`
-	Coellen1PlayerID *ID    `json:"coellen1PlayerID"`
-	Coellen2PlayerID *ID    `json:"coellen2PlayerID"`
-	Coellen3PlayerID *ID    `json:"coellen3PlayerID"`
-	Coellen4PlayerID *ID    `json:"coellen4PlayerID"`
+	Name               string              `json:"name"`
+	BoardVersionID     *ID                 `json:"boardVersionId"`
+	PrestigeSlotClaims []PrestigeSlotClaim `json:"prestigeSlotClaims"`
+}
+
+// Game state
+// A player's claim on a slot of the prestige table of the game's board version
+type PrestigeSlotClaim struct {
+	Model
+	GameID    ID `json:"gameId"`
+	SlotOrder int `json:"slotOrder"`
+	PlayerID  ID `json:"playerId"`
 }
 
 // Game state
@@ -0,0 +1,64 @@
+package app
+
+// PlayerRangeForm Changes to a PlayerRange. Fields that are left out are not changed,
+// so clients that don't know about player counts don't clear them.
+type PlayerRangeForm struct {
+	MinPlayers *int `json:"minPlayers" schema:"minPlayers"`
+	MaxPlayers *int `json:"maxPlayers" schema:"maxPlayers"`
+}
+
+// IsEmpty Whether the form leaves the range as it is
+func (f PlayerRangeForm) IsEmpty() bool {
+	return f.MinPlayers == nil && f.MaxPlayers == nil
+}
+
+// Apply The range with the form's changes made to it
+func (f PlayerRangeForm) Apply(r PlayerRange) PlayerRange {
+	if f.MinPlayers != nil {
+		r.MinPlayers = *f.MinPlayers
+	}
+	if f.MaxPlayers != nil {
+		r.MaxPlayers = *f.MaxPlayers
+	}
+	return r
+}
+
+func validatePlayerRange(form *Form, r PlayerRange) {
+	if r.MinPlayers < 0 {
+		form.AddError("MinPlayers", "must not be negative")
+	}
+	if r.MaxPlayers < 0 {
+		form.AddError("MaxPlayers", "must not be negative")
+	}
+	if r.MaxPlayers != 0 && r.MinPlayers > r.MaxPlayers {
+		form.AddError("MaxPlayers", "must not be less than the minimum number of players")
+	}
+}
+
+// ForPlayerCount Copy the board with only the cities and routes that are in play for the number of players.
+// Routes are left out along with either of the cities they connect.
+func (b *Board) ForPlayerCount(players int) (*Board, error) {
+	if players < 1 || !b.PlayerRange.Includes(players) {
+		return nil, ErrUnsupportedPlayerCount
+	}
+
+	filtered := *b
+	filtered.Cities = make([]City, 0, len(b.Cities))
+	filtered.Routes = make([]Route, 0, len(b.Routes))
+
+	inPlay := make(map[ID]bool, len(b.Cities))
+	for _, city := range b.Cities {
+		if city.PlayerRange.Includes(players) {
+			inPlay[city.ID] = true
+			filtered.Cities = append(filtered.Cities, city)
+		}
+	}
+
+	for _, route := range b.Routes {
+		if route.PlayerRange.Includes(players) && inPlay[route.StartCityID] && inPlay[route.EndCityID] {
+			filtered.Routes = append(filtered.Routes, route)
+		}
+	}
+
+	return &filtered, nil
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestPlayerRangeForm_Apply(t *testing.T) {
+	assert := assert.New(t)
+	two, five := 2, 5
+
+	r := PlayerRangeForm{}.Apply(PlayerRange{MinPlayers: 3, MaxPlayers: 4})
+	assert.That(r).IsEqualTo(PlayerRange{MinPlayers: 3, MaxPlayers: 4})
+
+	r = PlayerRangeForm{MaxPlayers: &five}.Apply(PlayerRange{MinPlayers: 3, MaxPlayers: 4})
+	assert.That(r).IsEqualTo(PlayerRange{MinPlayers: 3, MaxPlayers: 5})
+
+	r = PlayerRangeForm{MinPlayers: &two, MaxPlayers: &five}.Apply(PlayerRange{})
+	assert.That(r).IsEqualTo(PlayerRange{MinPlayers: 2, MaxPlayers: 5})
+}
+
+func TestValidatePlayerRange(t *testing.T) {
+	assert := assert.New(t)
+
+	form := Form{}
+	validatePlayerRange(&form, PlayerRange{MinPlayers: 3})
+	assert.ThatBool(form.HasError()).IsFalse()
+
+	form = Form{}
+	validatePlayerRange(&form, PlayerRange{MinPlayers: 4, MaxPlayers: 3})
+	assert.ThatInt(len(form.Errors["MaxPlayers"])).IsEqualTo(1)
+
+	form = Form{}
+	validatePlayerRange(&form, PlayerRange{MinPlayers: -1})
+	assert.ThatInt(len(form.Errors["MinPlayers"])).IsEqualTo(1)
+}
+
+func TestBoardForPlayerCount(t *testing.T) {
+	board := Board{
+		PlayerRange: PlayerRange{MinPlayers: 2, MaxPlayers: 5},
+		Cities: []City{
+			{Model: Model{ID: 1}, Name: "Lübeck"},
+			{Model: Model{ID: 2}, Name: "Hamburg"},
+			{Model: Model{ID: 3}, Name: "Bremen", PlayerRange: PlayerRange{MinPlayers: 4}},
+		},
+		Routes: []Route{
+			{Model: Model{ID: 1}, StartCityID: 1, EndCityID: 2},
+			{Model: Model{ID: 2}, StartCityID: 2, EndCityID: 3},
+			{Model: Model{ID: 3}, StartCityID: 1, EndCityID: 2, PlayerRange: PlayerRange{MaxPlayers: 3}},
+		},
+	}
+	assert := assert.New(t)
+
+	setup, err := board.ForPlayerCount(3)
+	assert.That(err).IsNil()
+	assert.ThatInt(len(setup.Cities)).IsEqualTo(2)
+	assert.ThatInt(len(setup.Routes)).IsEqualTo(2)
+
+	// Bremen comes into play, and the route to it along with it
+	setup, err = board.ForPlayerCount(4)
+	assert.That(err).IsNil()
+	assert.ThatInt(len(setup.Cities)).IsEqualTo(3)
+	assert.ThatInt(len(setup.Routes)).IsEqualTo(2)
+	assert.That(setup.Routes[1].ID).IsEqualTo(ID(2))
+
+	// The original board isn't changed
+	assert.ThatInt(len(board.Cities)).IsEqualTo(3)
+
+	for _, players := range []int{0, 1, 6} {
+		if _, err = board.ForPlayerCount(players); !errors.Is(err, ErrUnsupportedPlayerCount) {
+			t.Errorf("expected ErrUnsupportedPlayerCount for %d players, got %+v", players, err)
+		}
+	}
+}
+
+func TestUpdateCityValidatesPlayerRange(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}}},
+		SingletonCityResult: &City{
+			Model:       Model{ID: 3},
+			BoardID:     1,
+			Name:        "Lübeck",
+			PlayerRange: PlayerRange{MinPlayers: 4},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+	three := 3
+
+	form := CityForm{Name: "Lübeck", PlayerRangeForm: PlayerRangeForm{MaxPlayers: &three}}
+	_, err := service.UpdateCity(context.Background(), "3", &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+	assert.ThatInt(len(form.Errors["MaxPlayers"])).IsEqualTo(1)
+}
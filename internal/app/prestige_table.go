@@ -0,0 +1,77 @@
+package app
+
+import "fmt"
+
+// MaxPrestigeSlots The most slots a prestige table may have
+const MaxPrestigeSlots = 10
+
+// PrestigeTable A special scoring table on the board, hosted by one of its cities (Coellen in the original game).
+// A player with enough privilege who establishes a route into the city may claim the next free slot for its points.
+type PrestigeTable struct {
+	Model
+	BoardID ID             `json:"boardId"`
+	CityID  ID             `json:"cityId"`
+	Slots   []PrestigeSlot `json:"slots"`
+}
+
+// PrestigeSlot Part of a PrestigeTable. Games refer to slots by their Order.
+type PrestigeSlot struct {
+	Model
+	PrestigeTableID   ID  `json:"prestigeTableId"`
+	Order             int `json:"order"`
+	RequiredPrivilege int `json:"requiredPrivilege"`
+	Points            int `json:"points"`
+}
+
+// PrestigeTableForm Replaces a board's prestige table, slots and all. Slots are ordered by their position in the list.
+type PrestigeTableForm struct {
+	Form
+	CityID ID                 `json:"cityId"`
+	Slots  []PrestigeSlotForm `json:"slots"`
+}
+
+type PrestigeSlotForm struct {
+	RequiredPrivilege int `json:"requiredPrivilege"`
+	Points            int `json:"points"`
+}
+
+func (f *PrestigeTableForm) IsValid() bool {
+	if f.CityID == 0 {
+		f.AddError("CityID", "is required")
+	}
+
+	if len(f.Slots) == 0 {
+		f.AddError("Slots", "must not be empty")
+	} else if len(f.Slots) > MaxPrestigeSlots {
+		f.AddError("Slots", fmt.Sprintf("must not have more than %d slots", MaxPrestigeSlots))
+	}
+
+	for i, slot := range f.Slots {
+		field := fmt.Sprintf("Slots[%d]", i)
+		if slot.RequiredPrivilege < 1 || slot.RequiredPrivilege > 4 {
+			f.AddError(field, "requiredPrivilege is out of bounds (must be between 1 and 4)")
+		}
+		if slot.Points < 0 {
+			f.AddError(field, "points must not be negative")
+		}
+	}
+
+	return !f.HasError()
+}
+
+// PrestigeTable The table described by the form, for the board
+func (f *PrestigeTableForm) PrestigeTable(boardID ID) *PrestigeTable {
+	table := PrestigeTable{
+		BoardID: boardID,
+		CityID:  f.CityID,
+		Slots:   make([]PrestigeSlot, 0, len(f.Slots)),
+	}
+	for i, slot := range f.Slots {
+		table.Slots = append(table.Slots, PrestigeSlot{
+			Order:             i + 1,
+			RequiredPrivilege: slot.RequiredPrivilege,
+			Points:            slot.Points,
+		})
+	}
+	return &table
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestPrestigeTableFormIsValid(t *testing.T) {
+	assert := assert.New(t)
+
+	form := PrestigeTableForm{CityID: 1, Slots: []PrestigeSlotForm{{RequiredPrivilege: 1, Points: 7}}}
+	assert.ThatBool(form.IsValid()).IsTrue()
+
+	form = PrestigeTableForm{Slots: []PrestigeSlotForm{{RequiredPrivilege: 0, Points: -1}}}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	assert.ThatInt(len(form.Errors["CityID"])).IsEqualTo(1)
+	assert.ThatInt(len(form.Errors["Slots[0]"])).IsEqualTo(2)
+
+	form = PrestigeTableForm{CityID: 1}
+	assert.ThatBool(form.IsValid()).IsFalse()
+	assert.ThatInt(len(form.Errors["Slots"])).IsEqualTo(1)
+}
+
+func TestUpdatePrestigeTable(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}}, {Model: Model{ID: 2}}},
+		Cities: []City{
+			{Model: Model{ID: 3}, BoardID: 1, Name: "Coellen"},
+			{Model: Model{ID: 4}, BoardID: 2, Name: "Elsewhere"},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	form := PrestigeTableForm{
+		CityID: 3,
+		Slots: []PrestigeSlotForm{
+			{RequiredPrivilege: 1, Points: 7},
+			{RequiredPrivilege: 2, Points: 8},
+		},
+	}
+	table, err := service.UpdatePrestigeTable(context.Background(), "1", &form)
+	if err != nil {
+		t.Fatalf("UpdatePrestigeTable returned error: %+v", err)
+	}
+	assert.That(table.BoardID).IsEqualTo(ID(1))
+	assert.ThatInt(table.Slots[1].Order).IsEqualTo(2)
+	assert.ThatInt(table.Slots[1].Points).IsEqualTo(8)
+
+	form.CityID = 4
+	_, err = service.UpdatePrestigeTable(context.Background(), "1", &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+	assert.ThatInt(len(form.Errors["CityID"])).IsEqualTo(1)
+}
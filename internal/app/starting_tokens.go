@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+)
+
+// StartingTokenRoutesForm Lists the routes that get a bonus token at setup, in the order the tokens are placed.
+// Routes left out don't get one.
+type StartingTokenRoutesForm struct {
+	RouteIDs []ID `json:"routeIds"`
+	Form     `json:"-"`
+}
+
+// IsValidFor checks that every listed route is one of the board's routes, is listed once, and has a tavern for the token
+func (f *StartingTokenRoutesForm) IsValidFor(routes []Route) bool {
+	existing := make(map[ID]*Route, len(routes))
+	for i := range routes {
+		existing[routes[i].ID] = &routes[i]
+	}
+
+	seen := make(map[ID]bool, len(f.RouteIDs))
+	for _, id := range f.RouteIDs {
+		route, found := existing[id]
+		if !found {
+			f.AddError("RouteIDs", fmt.Sprintf("route %d does not belong to this board", id))
+		} else if seen[id] {
+			f.AddError("RouteIDs", fmt.Sprintf("route %d is listed more than once", id))
+		} else if !route.TavernFlag {
+			f.AddError("RouteIDs", fmt.Sprintf("route %d does not have a tavern", id))
+		}
+		seen[id] = true
+	}
+
+	return !f.HasError()
+}
+
+// StartingTokenOrder Where the route comes in the order starting bonus tokens are placed, or zero if it doesn't get one
+func (f *StartingTokenRoutesForm) StartingTokenOrder(routeID ID) int {
+	for i, id := range f.RouteIDs {
+		if id == routeID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// StartingTokenRoutes The routes that get a bonus token at setup, in the order the tokens are placed
+func (b *Board) StartingTokenRoutes() []Route {
+	routes := make([]Route, 0)
+	for _, route := range b.Routes {
+		if route.StartingTokenOrder > 0 {
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].StartingTokenOrder < routes[j].StartingTokenOrder
+	})
+	return routes
+}
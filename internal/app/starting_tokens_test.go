@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/assertgo/assert"
+)
+
+func TestStartingTokenRoutesFormIsValidFor(t *testing.T) {
+	assert := assert.New(t)
+	routes := []Route{
+		{Model: Model{ID: 1}, TavernFlag: true},
+		{Model: Model{ID: 2}, TavernFlag: true},
+		{Model: Model{ID: 3}},
+	}
+
+	form := StartingTokenRoutesForm{RouteIDs: []ID{2, 1}}
+	assert.ThatBool(form.IsValidFor(routes)).IsTrue()
+	assert.ThatInt(form.StartingTokenOrder(2)).IsEqualTo(1)
+	assert.ThatInt(form.StartingTokenOrder(1)).IsEqualTo(2)
+	assert.ThatInt(form.StartingTokenOrder(3)).IsEqualTo(0)
+
+	form = StartingTokenRoutesForm{RouteIDs: []ID{1, 1, 3, 4}}
+	assert.ThatBool(form.IsValidFor(routes)).IsFalse()
+	assert.ThatInt(len(form.Errors["RouteIDs"])).IsEqualTo(3)
+}
+
+func TestBoardStartingTokenRoutes(t *testing.T) {
+	assert := assert.New(t)
+	board := Board{Routes: []Route{
+		{Model: Model{ID: 1}, StartingTokenOrder: 2},
+		{Model: Model{ID: 2}},
+		{Model: Model{ID: 3}, StartingTokenOrder: 1},
+	}}
+
+	routes := board.StartingTokenRoutes()
+	assert.ThatInt(len(routes)).IsEqualTo(2)
+	assert.That(routes[0].ID).IsEqualTo(ID(3))
+	assert.That(routes[1].ID).IsEqualTo(ID(1))
+}
+
+func TestUpdateStartingTokenRoutes(t *testing.T) {
+	repo := fakeBoardCrudRepository{
+		Boards: []Board{{Model: Model{ID: 1}}},
+		Routes: []Route{
+			{Model: Model{ID: 1}, BoardID: 1, TavernFlag: true, StartingTokenOrder: 1},
+			{Model: Model{ID: 2}, BoardID: 1, TavernFlag: true},
+			{Model: Model{ID: 3}, BoardID: 1},
+		},
+	}
+	service := NewBoardEditorService(&repo, nil, nil)
+	assert := assert.New(t)
+
+	form := StartingTokenRoutesForm{RouteIDs: []ID{2}}
+	routes, err := service.UpdateStartingTokenRoutes(context.Background(), "1", &form)
+	if err != nil {
+		t.Fatalf("UpdateStartingTokenRoutes returned error: %+v", err)
+	}
+	assert.ThatInt(len(routes)).IsEqualTo(3)
+	assert.ThatInt(routes[0].StartingTokenOrder).IsEqualTo(0)
+	assert.ThatInt(routes[1].StartingTokenOrder).IsEqualTo(1)
+	assert.ThatInt(len(repo.BoardCommands)).IsEqualTo(1)
+	assert.That(repo.BoardCommands[0].Kind).IsEqualTo(CommandStartingTokens)
+	assert.ThatInt(len(repo.BoardCommands[0].Before.Steps)).IsEqualTo(2)
+	assert.ThatInt(repo.BoardCommands[0].Before.Steps[0].Route.StartingTokenOrder).IsEqualTo(1)
+
+	form = StartingTokenRoutesForm{RouteIDs: []ID{3}}
+	_, err = service.UpdateStartingTokenRoutes(context.Background(), "1", &form)
+	assert.ThatBool(errors.Is(err, ErrInvalidForm)).IsTrue()
+}
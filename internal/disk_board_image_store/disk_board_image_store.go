@@ -0,0 +1,98 @@
+package disk_board_image_store
+
+import (
+	"city-route-game/internal/app"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// NewDiskBoardImageStore Keep board images as files in dir, which is created if it doesn't exist.
+// Each image is saved next to a JSON file holding everything about it besides its contents.
+func NewDiskBoardImageStore(dir string) (app.BoardImageStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &diskBoardImageStore{
+		dir: dir,
+	}, nil
+}
+
+type diskBoardImageStore struct {
+	dir string
+}
+
+func (s diskBoardImageStore) imagePath(boardID app.ID) string {
+	return filepath.Join(s.dir, fmt.Sprintf("board-%d.img", boardID))
+}
+
+func (s diskBoardImageStore) metadataPath(boardID app.ID) string {
+	return filepath.Join(s.dir, fmt.Sprintf("board-%d.json", boardID))
+}
+
+func (s diskBoardImageStore) SaveBoardImage(ctx context.Context, image *app.BoardImage) error {
+	metadata, err := json.Marshal(image)
+	if err != nil {
+		return err
+	}
+
+	// The metadata is written last, since an image only exists once its metadata does
+	if err = s.writeFile(s.imagePath(image.BoardID), image.Data); err != nil {
+		return err
+	}
+	return s.writeFile(s.metadataPath(image.BoardID), metadata)
+}
+
+// writeFile Replace the file all at once, so it is never seen half written
+func (s diskBoardImageStore) writeFile(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s diskBoardImageStore) GetBoardImage(ctx context.Context, boardID app.ID) (*app.BoardImage, error) {
+	metadata, err := ioutil.ReadFile(s.metadataPath(boardID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, app.NewRecordNotFoundError("BoardImage", boardID)
+		}
+		return nil, err
+	}
+
+	var image app.BoardImage
+	if err = json.Unmarshal(metadata, &image); err != nil {
+		return nil, err
+	}
+
+	image.Data, err = ioutil.ReadFile(s.imagePath(boardID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+func (s diskBoardImageStore) DeleteBoardImage(ctx context.Context, boardID app.ID) error {
+	for _, path := range []string{s.metadataPath(boardID), s.imagePath(boardID)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
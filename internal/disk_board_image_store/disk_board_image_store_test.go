@@ -0,0 +1,74 @@
+package disk_board_image_store
+
+import (
+	"city-route-game/internal/app"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/assertgo/assert"
+)
+
+func TestSaveAndGetBoardImage(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDiskBoardImageStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := app.BoardImage{
+		BoardID:     1,
+		ContentType: "image/png",
+		Width:       640,
+		Height:      480,
+		Checksum:    "abc",
+		UpdatedAt:   time.Now().UTC().Truncate(time.Second),
+		Data:        []byte("first"),
+	}
+	if err = store.SaveBoardImage(ctx, &image); err != nil {
+		t.Fatal(err)
+	}
+
+	image.Data = []byte("second")
+	image.Checksum = "def"
+	if err = store.SaveBoardImage(ctx, &image); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := store.GetBoardImage(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert := assert.New(t)
+	assert.ThatString(found.ContentType).IsEqualTo("image/png")
+	assert.ThatInt(found.Width).IsEqualTo(640)
+	assert.ThatInt(found.Height).IsEqualTo(480)
+	assert.ThatString(found.Checksum).IsEqualTo("def")
+	assert.ThatString(string(found.Data)).IsEqualTo("second")
+	assert.ThatBool(found.UpdatedAt.Equal(image.UpdatedAt)).IsTrue()
+
+	_, err = store.GetBoardImage(ctx, 2)
+	assert.ThatBool(errors.Is(app.RecordNotFound{}, err)).IsTrue()
+}
+
+func TestDeleteBoardImage(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDiskBoardImageStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.SaveBoardImage(ctx, &app.BoardImage{BoardID: 1, Data: []byte("image")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert := assert.New(t)
+	assert.That(store.DeleteBoardImage(ctx, 1)).IsNil()
+	assert.That(store.DeleteBoardImage(ctx, 1)).IsNil()
+
+	_, err = store.GetBoardImage(ctx, 1)
+	assert.ThatBool(errors.Is(app.RecordNotFound{}, err)).IsTrue()
+}
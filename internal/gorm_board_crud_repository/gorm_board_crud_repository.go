@@ -4,7 +4,10 @@ import (
 	"city-route-game/internal/app"
 	"context"
 	"errors"
+	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
 )
 
 func NewGormBoardCrudRepository(db *gorm.DB) app.BoardCrudRepository {
@@ -17,6 +20,12 @@ type gormBoardRepository struct {
 	db *gorm.DB
 }
 
+func (p gormBoardRepository) Transaction(ctx context.Context, fn func(repo app.BoardCrudRepository) error) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormBoardRepository{db: tx})
+	})
+}
+
 func (p gormBoardRepository) GetBoardByID(ctx context.Context, id app.ID) (*app.Board, error) {
 	var board Board
 	if err := p.db.WithContext(ctx).First(&board, id).Error; err != nil {
@@ -29,6 +38,30 @@ func (p gormBoardRepository) GetBoardByID(ctx context.Context, id app.ID) (*app.
 	return newDomainBoardFromGormBoard(&board), nil
 }
 
+func (p gormBoardRepository) GetBoardGraphByID(ctx context.Context, id app.ID) (*app.Board, error) {
+	var board Board
+	err := p.db.WithContext(ctx).
+		Preload("Cities", func(cities *gorm.DB) *gorm.DB {
+			return cities.Order("id")
+		}).
+		Preload("Cities.CitySpaces", orderedSpaces).
+		Preload("Routes", func(routes *gorm.DB) *gorm.DB {
+			return routes.Order("id")
+		}).
+		Preload("Routes.RouteSpaces", orderedSpaces).
+		Preload("PrestigeTable").
+		Preload("PrestigeTable.Slots", orderedSpaces).
+		First(&board, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, app.NewBoardNotFoundError(id)
+		}
+		return nil, err
+	}
+
+	return newDomainBoardGraphFromGormBoard(&board), nil
+}
+
 func (p gormBoardRepository) CreateBoard(ctx context.Context, board *app.Board) error {
 	var gormBoard *Board
 	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -60,12 +93,164 @@ func (p gormBoardRepository) CreateBoard(ctx context.Context, board *app.Board)
 	board.Name = gormBoard.Name
 	board.CreatedAt = gormBoard.CreatedAt
 	board.UpdatedAt = gormBoard.UpdatedAt
+	board.Version = gormBoard.Version
 	board.Width = gormBoard.Width
 	board.Height = gormBoard.Height
 
 	return nil
 }
 
+func (p gormBoardRepository) CreateBoardGraph(ctx context.Context, board *app.Board) error {
+	var boardID ID
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var dupe Board
+		err := tx.First(&dupe, "name = ?", board.Name).Error
+		if err == nil {
+			return app.ErrNameTaken
+		}
+		if !errors.Is(gorm.ErrRecordNotFound, err) {
+			return err
+		}
+
+		gormBoard := Board{
+			Name:   board.Name,
+			Width:  board.Width,
+			Height: board.Height,
+			PlayerRange: PlayerRange{
+				MinPlayers: board.MinPlayers,
+				MaxPlayers: board.MaxPlayers,
+			},
+		}
+		if err = tx.Create(&gormBoard).Error; err != nil {
+			return err
+		}
+		boardID = gormBoard.ID
+
+		cityIDs := make(map[ID]ID, len(board.Cities))
+		for _, city := range board.Cities {
+			gormCity := City{
+				BoardID:  gormBoard.ID,
+				Name:     city.Name,
+				Position: Position{
+					X: city.Position.X,
+					Y: city.Position.Y,
+				},
+				PlayerRange: PlayerRange{
+					MinPlayers: city.MinPlayers,
+					MaxPlayers: city.MaxPlayers,
+				},
+				Upgrade: string(city.Upgrade),
+			}
+			if err = tx.Omit(clause.Associations).Create(&gormCity).Error; err != nil {
+				return err
+			}
+			cityIDs[city.ID] = gormCity.ID
+
+			for _, space := range city.CitySpaces {
+				gormSpace := CitySpace{
+					CityID:            gormCity.ID,
+					Order:             space.Order,
+					SpaceType:         space.SpaceType,
+					RequiredPrivilege: space.RequiredPrivilege,
+				}
+				if err = tx.Create(&gormSpace).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, route := range board.Routes {
+			startCityID, startFound := cityIDs[route.StartCityID]
+			endCityID, endFound := cityIDs[route.EndCityID]
+			if !startFound || !endFound {
+				return &constraintViolation{
+					msg: fmt.Sprintf("constraint violation: route between cities %d and %d refers to a city not in the board", route.StartCityID, route.EndCityID),
+				}
+			}
+
+			gormRoute := Route{
+				BoardID:     gormBoard.ID,
+				StartCityID: startCityID,
+				EndCityID:   endCityID,
+				TavernFlag:  route.TavernFlag,
+				PlayerRange: PlayerRange{
+					MinPlayers: route.MinPlayers,
+					MaxPlayers: route.MaxPlayers,
+				},
+				StartingTokenOrder: route.StartingTokenOrder,
+			}
+			if err = tx.Omit(clause.Associations).Create(&gormRoute).Error; err != nil {
+				return err
+			}
+
+			for _, space := range route.RouteSpaces {
+				gormSpace := RouteSpace{
+					RouteID: gormRoute.ID,
+					Order:   space.Order,
+				}
+				if err = tx.Create(&gormSpace).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if board.ConnectionObjective != nil {
+			startCityID, startFound := cityIDs[board.ConnectionObjective.StartCityID]
+			endCityID, endFound := cityIDs[board.ConnectionObjective.EndCityID]
+			if !startFound || !endFound {
+				return &constraintViolation{
+					msg: fmt.Sprintf("constraint violation: connection objective between cities %d and %d refers to a city not in the board",
+						board.ConnectionObjective.StartCityID, board.ConnectionObjective.EndCityID),
+				}
+			}
+
+			objective := *board.ConnectionObjective
+			objective.StartCityID = startCityID
+			objective.EndCityID = endCityID
+			gormBoard.ConnectionObjective = newGormConnectionObjective(&objective)
+			err = tx.Model(&gormBoard).
+				Select("ConnectionStartCityID", "ConnectionEndCityID", "ConnectionAwards").
+				Updates(&gormBoard).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if board.PrestigeTable != nil {
+			cityID, found := cityIDs[board.PrestigeTable.CityID]
+			if !found {
+				return &constraintViolation{
+					msg: fmt.Sprintf("constraint violation: prestige table refers to city %d, which is not in the board", board.PrestigeTable.CityID),
+				}
+			}
+
+			gormTable := newGormPrestigeTableFromAppPrestigeTable(board.PrestigeTable)
+			gormTable.Model = Model{}
+			gormTable.BoardID = gormBoard.ID
+			gormTable.CityID = cityID
+			for i := range gormTable.Slots {
+				gormTable.Slots[i].Model = Model{}
+			}
+			if err = tx.Create(gormTable).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	created, err := p.GetBoardGraphByID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	*board = *created
+
+	return nil
+}
+
 func (p gormBoardRepository) UpdateBoard(ctx context.Context, id app.ID, updateFn func(board *app.Board) (*app.Board, error)) (*app.Board, error) {
 	var domainBoard *app.Board
 	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -102,11 +287,19 @@ func (p gormBoardRepository) UpdateBoard(ctx context.Context, id app.ID, updateF
 			return err
 		}
 
-		err = tx.Save(updatedGormBoard).Error
-		if err != nil {
-			return err
+		// Only save over the version that was loaded, in case someone else saved in the meantime
+		updatedGormBoard.Version = board.Version + 1
+		result := tx.Where("version = ?", board.Version).Select("*").Updates(updatedGormBoard)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return app.NewStaleRecordError("Board", id)
 		}
 
+		domainBoard.Version = updatedGormBoard.Version
+		domainBoard.UpdatedAt = updatedGormBoard.UpdatedAt
+
 		return nil
 	})
 	if err != nil {
@@ -131,6 +324,56 @@ func (p gormBoardRepository) ListBoards(ctx context.Context) ([]app.Board, error
 	return domainBoards, nil
 }
 
+// boardSortColumns The column each way of sorting boards orders by
+var boardSortColumns = map[app.BoardSort]string{
+	app.BoardSortName:    "name",
+	app.BoardSortCreated: "created_at",
+	app.BoardSortUpdated: "updated_at",
+}
+
+// likeEscaper Escapes the characters that have a special meaning in a LIKE pattern, so they match themselves
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (p gormBoardRepository) SearchBoards(ctx context.Context, query app.BoardQuery) ([]app.Board, int, error) {
+	column, found := boardSortColumns[query.Sort]
+	if !found {
+		column = boardSortColumns[app.BoardSortName]
+	}
+
+	// Counting changes the statement, so each query starts over from the same filter
+	matching := func() *gorm.DB {
+		tx := p.db.WithContext(ctx).Model(&Board{})
+		if query.Name != "" {
+			pattern := "%" + likeEscaper.Replace(strings.ToLower(query.Name)) + "%"
+			tx = tx.Where(`LOWER(name) LIKE ? ESCAPE '\'`, pattern)
+		}
+		return tx
+	}
+
+	var total int64
+	if err := matching().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var boards []Board
+	err := matching().
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: query.Descending}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: query.Descending}).
+		Offset(query.Offset).
+		Limit(query.Limit).
+		Find(&boards).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	domainBoards := make([]app.Board, 0, len(boards))
+	for _, board := range boards {
+		domainBoards = append(domainBoards, *newDomainBoardFromGormBoard(&board))
+	}
+
+	return domainBoards, int(total), nil
+}
+
 func (p gormBoardRepository) DeleteBoardByID(ctx context.Context, id app.ID) error {
 	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var board Board
@@ -143,6 +386,14 @@ func (p gormBoardRepository) DeleteBoardByID(ctx context.Context, id app.ID) err
 			return err
 		}
 
+		games, err := countBoardGames(tx, id)
+		if err != nil {
+			return err
+		}
+		if games > 0 {
+			return app.NewBoardInUseError(id, games)
+		}
+
 		if err = tx.Delete(&board).Error; err != nil {
 			return err
 		}
@@ -151,6 +402,58 @@ func (p gormBoardRepository) DeleteBoardByID(ctx context.Context, id app.ID) err
 	})
 }
 
+func (p gormBoardRepository) GetBoardDeleteImpact(ctx context.Context, id app.ID) (*app.BoardDeleteImpact, error) {
+	tx := p.db.WithContext(ctx)
+
+	var board Board
+	if err := tx.First(&board, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, app.NewBoardNotFoundError(id)
+		}
+		return nil, err
+	}
+
+	cityIDs := tx.Model(&City{}).Select("id").Where("board_id = ?", id)
+	routeIDs := tx.Model(&Route{}).Select("id").Where("board_id = ?", id)
+	impact := app.BoardDeleteImpact{BoardID: id}
+	counts := []struct {
+		count *int
+		query *gorm.DB
+	}{
+		{&impact.Cities, tx.Model(&City{}).Where("board_id = ?", id)},
+		{&impact.CitySpaces, tx.Model(&CitySpace{}).Where("city_id IN (?)", cityIDs)},
+		{&impact.Routes, tx.Model(&Route{}).Where("board_id = ?", id)},
+		{&impact.RouteSpaces, tx.Model(&RouteSpace{}).Where("route_id IN (?)", routeIDs)},
+		{&impact.Versions, tx.Model(&BoardVersion{}).Where("board_id = ?", id)},
+	}
+	for _, c := range counts {
+		var count int64
+		if err := c.query.Count(&count).Error; err != nil {
+			return nil, err
+		}
+		*c.count = int(count)
+	}
+
+	games, err := countBoardGames(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	impact.Games = games
+	impact.Deletable = games == 0
+
+	return &impact, nil
+}
+
+// countBoardGames Count the games played on any version of the board
+func countBoardGames(tx *gorm.DB, boardID app.ID) (int, error) {
+	var count int64
+	versionIDs := tx.Model(&BoardVersion{}).Select("id").Where("board_id = ?", boardID)
+	if err := tx.Model(&Game{}).Where("board_version_id IN (?)", versionIDs).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
 //func (p *gormBoardRepository) BoardExistsWithName(name string) (bool, error) {
 //	var dupe domain.Board
 //	err := p.db.Where("name = ?", name).Take(&dupe).Error
@@ -175,6 +478,93 @@ func (p gormBoardRepository) DeleteBoardByID(ctx context.Context, id app.ID) err
 //	}
 //}
 
+func (p gormBoardRepository) CreateBoardVersion(ctx context.Context, version *app.BoardVersion) error {
+	gormVersion, err := newGormBoardVersionFromAppBoardVersion(version)
+	if err != nil {
+		return err
+	}
+
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var board Board
+		if err := tx.First(&board, version.BoardID).Error; err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewBoardNotFoundError(version.BoardID)
+			}
+			return err
+		}
+
+		var latest int
+		err := tx.Model(&BoardVersion{}).
+			Where("board_id = ?", version.BoardID).
+			Select("COALESCE(MAX(number), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		gormVersion.Number = latest + 1
+		return tx.Create(gormVersion).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	created, err := newAppBoardVersionFromGormBoardVersion(gormVersion)
+	if err != nil {
+		return err
+	}
+	*version = *created
+
+	return nil
+}
+
+func (p gormBoardRepository) ListBoardVersionsByBoardID(ctx context.Context, boardID app.ID) ([]app.BoardVersion, error) {
+	var versions []BoardVersion
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var board Board
+		if err := tx.First(&board, boardID).Error; err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewBoardNotFoundError(boardID)
+			}
+			return err
+		}
+
+		return tx.Where("board_id = ?", boardID).Order("number").Find(&versions).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]app.BoardVersion, 0, len(versions))
+	for _, version := range versions {
+		appVersion, err := newAppBoardVersionFromGormBoardVersion(&version)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *appVersion)
+	}
+
+	return result, nil
+}
+
+func (p gormBoardRepository) GetBoardVersionByNumber(ctx context.Context, boardID app.ID, number int) (*app.BoardVersion, error) {
+	var version BoardVersion
+	err := p.db.WithContext(ctx).
+		Where("board_id = ? AND number = ?", boardID, number).
+		First(&version).Error
+	if err != nil {
+		if errors.Is(gorm.ErrRecordNotFound, err) {
+			return nil, &app.RecordNotFound{
+				Name: fmt.Sprintf("Version %d of Board", number),
+				ID:   boardID,
+			}
+		}
+		return nil, err
+	}
+
+	return newAppBoardVersionFromGormBoardVersion(&version)
+}
+
 func (p gormBoardRepository) ListCitiesByBoardID(ctx context.Context, boardID app.ID) ([]app.City, error) {
 	var cities []City
 	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -217,6 +607,9 @@ func (p gormBoardRepository) GetCityByID(ctx context.Context, id app.ID) (*app.C
 			return spaces.Order("`city_spaces`.`order` ASC")
 		}).First(&city, id).Error
 	if err != nil {
+		if errors.Is(gorm.ErrRecordNotFound, err) {
+			return nil, app.NewRecordNotFoundError("City", id)
+		}
 		return nil, err
 	}
 
@@ -236,6 +629,7 @@ func (p gormBoardRepository) CreateCity(ctx context.Context, city *app.City) err
 	city.ID = gormCity.ID
 	city.CreatedAt = gormCity.CreatedAt
 	city.UpdatedAt = gormCity.UpdatedAt
+	city.Version = gormCity.Version
 	city.Name = gormCity.Name
 	city.BoardID = gormCity.BoardID
 	city.Position.X = gormCity.Position.X
@@ -274,12 +668,18 @@ func (p gormBoardRepository) UpdateCity(ctx context.Context, id app.ID, updateFn
 			return err
 		}
 
-		err = tx.Save(updatedGormCity).Error
-		if err != nil {
-			return err
+		// Only save over the version that was loaded, in case someone else saved in the meantime
+		updatedGormCity.Version = city.Version + 1
+		result := tx.Omit(clause.Associations).Where("version = ?", city.Version).Select("*").Updates(updatedGormCity)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return app.NewStaleRecordError("City", id)
 		}
 
 		updatedCity.UpdatedAt = updatedGormCity.UpdatedAt
+		updatedCity.Version = updatedGormCity.Version
 
 		return nil
 	})
@@ -355,38 +755,52 @@ func (p gormBoardRepository) CreateCitySpace(ctx context.Context, citySpace *app
 	return nil
 }
 
-func (p gormBoardRepository) UpdateCitySpace(ctx context.Context, id app.ID, updateFn func(*app.CitySpace) (*app.CitySpace, error)) error {
-	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var citySpace *app.CitySpace
+func (p gormBoardRepository) UpdateCitySpace(ctx context.Context, id app.ID, updateFn func(*app.CitySpace) (*app.CitySpace, error)) (*app.CitySpace, error) {
+	var updatedSpace *app.CitySpace
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var space CitySpace
 
-		err := tx.First(citySpace, id).Error
+		err := tx.First(&space, id).Error
 		if err != nil {
 			if errors.Is(gorm.ErrRecordNotFound, err) {
-				return app.RecordNotFound{
-					Name: "CitySpace",
-					ID:   id,
-				}
+				return app.NewRecordNotFoundError("CitySpace", id)
 			}
 			return err
 		}
 
-		citySpace, err = updateFn(citySpace)
+		updatedSpace, err = updateFn(newAppCitySpaceFromGormCitySpace(&space))
+		if err != nil {
+			return err
+		}
+		if updatedSpace == nil {
+			panic("updateFn returned nil error and nil space")
+		}
+
+		updatedGormSpace, err := newGormCitySpaceFromAppCitySpace(updatedSpace)
 		if err != nil {
 			return err
 		}
 
-		err = tx.Save(citySpace).Error
+		err = tx.Save(updatedGormSpace).Error
 		if err != nil {
 			return err
 		}
 
+		updatedSpace.UpdatedAt = updatedGormSpace.UpdatedAt
+
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedSpace, nil
 }
 
 func (p gormBoardRepository) GetCitySpacesByCityID(ctx context.Context, cityID app.ID) ([]app.CitySpace, error) {
 	var spaces []CitySpace
 	if err := p.db.WithContext(ctx).
+		Scopes(orderedSpaces).
 		Find(&spaces, "city_id = ?", cityID).
 		Error; err != nil {
 		return nil, err
@@ -401,6 +815,18 @@ func (p gormBoardRepository) GetCitySpacesByCityID(ctx context.Context, cityID a
 	return appSpaces, nil
 }
 
+func (p gormBoardRepository) GetCitySpaceByID(ctx context.Context, id app.ID) (*app.CitySpace, error) {
+	var space CitySpace
+	if err := p.db.WithContext(ctx).First(&space, id).Error; err != nil {
+		if errors.Is(gorm.ErrRecordNotFound, err) {
+			return nil, app.NewRecordNotFoundError("CitySpace", id)
+		}
+		return nil, err
+	}
+
+	return newAppCitySpaceFromGormCitySpace(&space), nil
+}
+
 func (p gormBoardRepository) DeleteCitySpaceByID(ctx context.Context, id app.ID) error {
 	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var space CitySpace
@@ -423,3 +849,517 @@ func (p gormBoardRepository) DeleteCitySpaceByID(ctx context.Context, id app.ID)
 		return nil
 	})
 }
+
+// orderedSpaces Preload condition that sorts spaces by their position along the route or within the city
+func orderedSpaces(spaces *gorm.DB) *gorm.DB {
+	return spaces.Order(clause.OrderByColumn{Column: clause.Column{Name: "order"}})
+}
+
+func (p gormBoardRepository) ListRoutesByBoardID(ctx context.Context, boardID app.ID) ([]app.Route, error) {
+	var routes []Route
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var board Board
+		err := tx.First(&board, boardID).Error
+		if err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewBoardNotFoundError(boardID)
+			}
+			return err
+		}
+
+		return tx.Model(&Route{}).Preload("RouteSpaces", orderedSpaces).
+			Where("board_id = ?", boardID).
+			Order("id").
+			Find(&routes).
+			Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	appRoutes := make([]app.Route, 0, len(routes))
+	for _, route := range routes {
+		appRoutes = append(appRoutes, *newAppRouteFromGormRoute(&route))
+	}
+
+	return appRoutes, nil
+}
+
+func (p gormBoardRepository) GetRouteByID(ctx context.Context, id app.ID) (*app.Route, error) {
+	var route Route
+	err := p.db.WithContext(ctx).Model(&Route{}).
+		Preload("RouteSpaces", orderedSpaces).
+		First(&route, id).Error
+	if err != nil {
+		if errors.Is(gorm.ErrRecordNotFound, err) {
+			return nil, app.NewRecordNotFoundError("Route", id)
+		}
+		return nil, err
+	}
+
+	return newAppRouteFromGormRoute(&route), nil
+}
+
+func (p gormBoardRepository) CreateRoute(ctx context.Context, route *app.Route) error {
+	gormRoute, err := newGormRouteFromAppRoute(route)
+	if err != nil {
+		return err
+	}
+
+	if err := p.db.WithContext(ctx).Save(gormRoute).Error; err != nil {
+		return err
+	}
+
+	*route = *newAppRouteFromGormRoute(gormRoute)
+
+	return nil
+}
+
+func (p gormBoardRepository) UpdateRoute(ctx context.Context, id app.ID, updateFn func(route *app.Route) (*app.Route, error)) (*app.Route, error) {
+	var updatedRoute *app.Route
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var route Route
+		err := tx.Preload("RouteSpaces", orderedSpaces).First(&route, id).Error
+		if err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewRecordNotFoundError("Route", id)
+			}
+			return err
+		}
+
+		updatedRoute, err = updateFn(newAppRouteFromGormRoute(&route))
+		if err != nil {
+			return err
+		}
+		if updatedRoute == nil {
+			panic("updateFn returned nil error and nil route")
+		}
+
+		updatedGormRoute, err := newGormRouteFromAppRoute(updatedRoute)
+		if err != nil {
+			return err
+		}
+
+		// Spaces are managed separately; only save the route's own columns
+		err = tx.Omit(clause.Associations).Save(updatedGormRoute).Error
+		if err != nil {
+			return err
+		}
+
+		updatedRoute.UpdatedAt = updatedGormRoute.UpdatedAt
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedRoute, nil
+}
+
+func (p gormBoardRepository) DeleteRouteByID(ctx context.Context, id app.ID) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var route Route
+		err := tx.First(&route, id).Error
+		if err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewRecordNotFoundError("Route", id)
+			}
+			return err
+		}
+
+		return tx.Delete(&route).Error
+	})
+}
+
+func (p gormBoardRepository) UpdateRouteSpaces(ctx context.Context, routeID app.ID, updateFn func(route *app.Route) (*app.Route, error)) (*app.Route, error) {
+	var updatedRoute *app.Route
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var route Route
+		err := tx.Preload("RouteSpaces", orderedSpaces).First(&route, routeID).Error
+		if err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewRecordNotFoundError("Route", routeID)
+			}
+			return err
+		}
+
+		result, err := updateFn(newAppRouteFromGormRoute(&route))
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			panic("updateFn returned nil error and nil route")
+		}
+
+		updatedGormRoute, err := newGormRouteFromAppRoute(result)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Omit(clause.Associations).Save(updatedGormRoute).Error; err != nil {
+			return err
+		}
+
+		if err = replaceRouteSpaces(tx, &route, updatedGormRoute.RouteSpaces); err != nil {
+			return err
+		}
+
+		var reloaded Route
+		if err = tx.Preload("RouteSpaces", orderedSpaces).First(&reloaded, routeID).Error; err != nil {
+			return err
+		}
+		updatedRoute = newAppRouteFromGormRoute(&reloaded)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedRoute, nil
+}
+
+func (p gormBoardRepository) GetPrestigeTableByBoardID(ctx context.Context, boardID app.ID) (*app.PrestigeTable, error) {
+	var table PrestigeTable
+	err := p.db.WithContext(ctx).
+		Preload("Slots", orderedSpaces).
+		First(&table, "board_id = ?", boardID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, app.NewRecordNotFoundError("PrestigeTable", boardID)
+		}
+		return nil, err
+	}
+
+	return newAppPrestigeTableFromGormPrestigeTable(&table), nil
+}
+
+func (p gormBoardRepository) SavePrestigeTable(ctx context.Context, table *app.PrestigeTable) error {
+	var gormTable *PrestigeTable
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing PrestigeTable
+		err := tx.First(&existing, "board_id = ?", table.BoardID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		gormTable = newGormPrestigeTableFromAppPrestigeTable(table)
+		gormTable.ID = existing.ID
+		gormTable.CreatedAt = existing.CreatedAt
+		slots := gormTable.Slots
+		gormTable.Slots = nil
+
+		if err = tx.Omit(clause.Associations).Save(gormTable).Error; err != nil {
+			return err
+		}
+
+		// Slots are always replaced, since they are only ever edited as a whole
+		if err = tx.Delete(&PrestigeSlot{}, "prestige_table_id = ?", gormTable.ID).Error; err != nil {
+			return err
+		}
+		for i := range slots {
+			slots[i].ID = 0
+			slots[i].PrestigeTableID = gormTable.ID
+		}
+		if len(slots) > 0 {
+			if err = tx.Create(&slots).Error; err != nil {
+				return err
+			}
+		}
+		gormTable.Slots = slots
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*table = *newAppPrestigeTableFromGormPrestigeTable(gormTable)
+	return nil
+}
+
+func (p gormBoardRepository) DeletePrestigeTableByBoardID(ctx context.Context, boardID app.ID) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var table PrestigeTable
+		if err := tx.First(&table, "board_id = ?", boardID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return app.NewRecordNotFoundError("PrestigeTable", boardID)
+			}
+			return err
+		}
+
+		return tx.Delete(&table).Error
+	})
+}
+
+// replaceRouteSpaces Make the route's spaces match "desired" exactly, numbering them by their position in the slice.
+// Kept spaces are first moved to temporary negative orders so that renumbering never collides
+// with uidx_route_space_route_order part way through.
+func replaceRouteSpaces(tx *gorm.DB, route *Route, desired []RouteSpace) error {
+	current := make(map[ID]bool, len(route.RouteSpaces))
+	for _, space := range route.RouteSpaces {
+		current[space.ID] = true
+	}
+
+	kept := make(map[ID]bool, len(desired))
+	for _, space := range desired {
+		if space.ID == 0 {
+			continue
+		}
+		if !current[space.ID] {
+			return &constraintViolation{
+				msg: fmt.Sprintf("constraint violation: route space %d does not belong to route %d", space.ID, route.ID),
+			}
+		}
+		kept[space.ID] = true
+	}
+
+	for _, space := range route.RouteSpaces {
+		if !kept[space.ID] {
+			if err := tx.Delete(&RouteSpace{}, space.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, space := range desired {
+		if space.ID == 0 {
+			continue
+		}
+		err := tx.Model(&RouteSpace{}).Where("id = ?", space.ID).Update("order", -(i + 1)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, space := range desired {
+		order := i + 1
+		if space.ID == 0 {
+			newSpace := RouteSpace{
+				RouteID: route.ID,
+				Order:   order,
+			}
+			if err := tx.Create(&newSpace).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := tx.Model(&RouteSpace{}).Where("id = ?", space.ID).Update("order", order).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p gormBoardRepository) ReorderCitySpaces(ctx context.Context, cityID app.ID, spaceIDs []app.ID) ([]app.CitySpace, error) {
+	var spaces []CitySpace
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var city City
+		if err := tx.First(&city, cityID).Error; err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewRecordNotFoundError("City", cityID)
+			}
+			return err
+		}
+
+		var current []CitySpace
+		if err := tx.Find(&current, "city_id = ?", cityID).Error; err != nil {
+			return err
+		}
+
+		belongs := make(map[ID]bool, len(current))
+		for _, space := range current {
+			belongs[space.ID] = true
+		}
+		if len(spaceIDs) != len(current) {
+			return &constraintViolation{
+				msg: fmt.Sprintf("constraint violation: reorder of city %d must list all %d spaces", cityID, len(current)),
+			}
+		}
+		for _, id := range spaceIDs {
+			if !belongs[id] {
+				return &constraintViolation{
+					msg: fmt.Sprintf("constraint violation: city space %d does not belong to city %d", id, cityID),
+				}
+			}
+		}
+
+		// Move every space out of the way first so uidx_city_space_city_id_order never sees a duplicate
+		for i, id := range spaceIDs {
+			if err := tx.Model(&CitySpace{}).Where("id = ?", id).Update("order", -(i + 1)).Error; err != nil {
+				return err
+			}
+		}
+		for i, id := range spaceIDs {
+			if err := tx.Model(&CitySpace{}).Where("id = ?", id).Update("order", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Scopes(orderedSpaces).Find(&spaces, "city_id = ?", cityID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	appSpaces := make([]app.CitySpace, 0, len(spaces))
+	for _, space := range spaces {
+		appSpaces = append(appSpaces, *newAppCitySpaceFromGormCitySpace(&space))
+	}
+
+	return appSpaces, nil
+}
+
+func (p gormBoardRepository) RestoreCity(ctx context.Context, city *app.City, routes []app.Route) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		gormCity, err := newGormCityFromAppCity(city)
+		if err != nil {
+			return err
+		}
+		if err = tx.Omit(clause.Associations).Create(gormCity).Error; err != nil {
+			return err
+		}
+		if len(gormCity.CitySpaces) > 0 {
+			if err = tx.Create(gormCity.CitySpaces).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, route := range routes {
+			gormRoute, err := newGormRouteFromAppRoute(&route)
+			if err != nil {
+				return err
+			}
+			if err = tx.Omit(clause.Associations).Create(gormRoute).Error; err != nil {
+				return err
+			}
+			if len(gormRoute.RouteSpaces) > 0 {
+				if err = tx.Create(gormRoute.RouteSpaces).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p gormBoardRepository) RestoreCitySpaces(ctx context.Context, cityID app.ID, spaces []app.CitySpace) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var city City
+		if err := tx.First(&city, cityID).Error; err != nil {
+			if errors.Is(gorm.ErrRecordNotFound, err) {
+				return app.NewRecordNotFoundError("City", cityID)
+			}
+			return err
+		}
+
+		if err := tx.Delete(&CitySpace{}, "city_id = ?", cityID).Error; err != nil {
+			return err
+		}
+
+		for _, space := range spaces {
+			gormSpace, err := newGormCitySpaceFromAppCitySpace(&space)
+			if err != nil {
+				return err
+			}
+			if err = tx.Create(gormSpace).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p gormBoardRepository) RestoreRoute(ctx context.Context, route *app.Route) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		gormRoute, err := newGormRouteFromAppRoute(route)
+		if err != nil {
+			return err
+		}
+
+		// Save updates the route if it still exists and creates it again otherwise
+		if err = tx.Omit(clause.Associations).Save(gormRoute).Error; err != nil {
+			return err
+		}
+		if err = tx.Delete(&RouteSpace{}, "route_id = ?", route.ID).Error; err != nil {
+			return err
+		}
+		if len(gormRoute.RouteSpaces) > 0 {
+			if err = tx.Create(gormRoute.RouteSpaces).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p gormBoardRepository) CreateBoardCommand(ctx context.Context, command *app.BoardCommand) error {
+	gormCommand, err := newGormBoardCommandFromAppBoardCommand(command)
+	if err != nil {
+		return err
+	}
+
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("board_id = ? AND undone = ?", command.BoardID, true).
+			Delete(&BoardCommand{}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(gormCommand).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	command.ID = gormCommand.ID
+	command.CreatedAt = gormCommand.CreatedAt
+	command.UpdatedAt = gormCommand.UpdatedAt
+
+	return nil
+}
+
+func (p gormBoardRepository) GetLastBoardCommand(ctx context.Context, boardID app.ID) (*app.BoardCommand, error) {
+	return p.findBoardCommand(ctx, boardID, false, "id DESC")
+}
+
+func (p gormBoardRepository) GetLastUndoneBoardCommand(ctx context.Context, boardID app.ID) (*app.BoardCommand, error) {
+	// Commands are undone newest first, so the oldest undone command is the one undone most recently
+	return p.findBoardCommand(ctx, boardID, true, "id ASC")
+}
+
+func (p gormBoardRepository) findBoardCommand(ctx context.Context, boardID app.ID, undone bool, order string) (*app.BoardCommand, error) {
+	var command BoardCommand
+	err := p.db.WithContext(ctx).
+		Where("board_id = ? AND undone = ?", boardID, undone).
+		Order(order).
+		First(&command).Error
+	if err != nil {
+		if errors.Is(gorm.ErrRecordNotFound, err) {
+			return nil, app.NewRecordNotFoundError("BoardCommand", boardID)
+		}
+		return nil, err
+	}
+
+	return newAppBoardCommandFromGormBoardCommand(&command)
+}
+
+func (p gormBoardRepository) SetBoardCommandUndone(ctx context.Context, id app.ID, undone bool) error {
+	result := p.db.WithContext(ctx).Model(&BoardCommand{}).
+		Where("id = ?", id).
+		Update("undone", undone)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return app.NewRecordNotFoundError("BoardCommand", id)
+	}
+	return nil
+}
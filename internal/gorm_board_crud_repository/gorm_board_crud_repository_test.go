@@ -49,6 +49,124 @@ func TempTransaction(callback func (app.BoardCrudRepository, *gorm.DB)) {
 	}
 }
 
+// legacyGame How games were stored before the prestige table replaced the Coellen columns
+type legacyGame struct {
+	Model
+	Name             string `gorm:"not null;index"`
+	Coellen1PlayerID *ID
+	Coellen2PlayerID *ID
+	Coellen3PlayerID *ID
+	Coellen4PlayerID *ID
+}
+
+func (legacyGame) TableName() string {
+	return "games"
+}
+
+func TestMigrateCoellenClaims(t *testing.T) {
+	assert := assert.New(t)
+	legacyDB, err := gorm.Open(sqlite.Open("file:legacy?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = legacyDB.AutoMigrate(&legacyGame{}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, third := ID(11), ID(12)
+	game := legacyGame{Name: "Old Game", Coellen1PlayerID: &first, Coellen3PlayerID: &third}
+	if err = legacyDB.Create(&game).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Migrate(legacyDB); err != nil {
+		t.Fatalf("Migrate returned error: %+v", err)
+	}
+
+	var claims []PrestigeSlotClaim
+	if err = legacyDB.Order("slot_order").Find(&claims, "game_id = ?", game.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.ThatInt(len(claims)).IsEqualTo(2)
+	assert.ThatInt(claims[0].SlotOrder).IsEqualTo(1)
+	assert.That(claims[0].PlayerID).IsEqualTo(first)
+	assert.ThatInt(claims[1].SlotOrder).IsEqualTo(3)
+	assert.That(claims[1].PlayerID).IsEqualTo(third)
+	assert.ThatBool(legacyDB.Migrator().HasColumn(&Game{}, "coellen1_player_id")).IsFalse()
+
+	// Running it again finds nothing left to move
+	if err = Migrate(legacyDB); err != nil {
+		t.Fatalf("Migrate returned error: %+v", err)
+	}
+}
+
+// legacyBoard How boards were stored when they pointed at the game played on them
+type legacyBoard struct {
+	Model
+	Name   string `gorm:"not null;uniqueIndex"`
+	GameID *ID    `gorm:"index"`
+	Width  int    `gorm:"not null;default:0"`
+	Height int    `gorm:"not null;default:0"`
+}
+
+func (legacyBoard) TableName() string {
+	return "boards"
+}
+
+func TestMigrateBoardGameLinks(t *testing.T) {
+	assert := assert.New(t)
+	legacyDB, err := gorm.Open(sqlite.Open("file:legacy-boards?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = legacyDB.AutoMigrate(&legacyGame{}, &legacyBoard{}); err != nil {
+		t.Fatal(err)
+	}
+
+	game := legacyGame{Name: "Old Game"}
+	if err = legacyDB.Create(&game).Error; err != nil {
+		t.Fatal(err)
+	}
+	linked := legacyBoard{Name: "Played Board", GameID: &game.ID, Width: 100, Height: 50}
+	unlinked := legacyBoard{Name: "Unplayed Board"}
+	if err = legacyDB.Create(&linked).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err = legacyDB.Create(&unlinked).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Migrate(legacyDB); err != nil {
+		t.Fatalf("Migrate returned error: %+v", err)
+	}
+
+	var versions []BoardVersion
+	if err = legacyDB.Find(&versions).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.ThatInt(len(versions)).IsEqualTo(1)
+	assert.That(versions[0].BoardID).IsEqualTo(linked.ID)
+	assert.ThatInt(versions[0].Number).IsEqualTo(1)
+
+	var migrated Game
+	if err = legacyDB.First(&migrated, game.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if migrated.BoardVersionID == nil || *migrated.BoardVersionID != versions[0].ID {
+		t.Errorf("the game should be linked to the published version, was: %v", migrated.BoardVersionID)
+	}
+	assert.ThatBool(legacyDB.Migrator().HasColumn(&Board{}, "game_id")).IsFalse()
+
+	// Running it again finds nothing left to move
+	if err = Migrate(legacyDB); err != nil {
+		t.Fatalf("Migrate returned error: %+v", err)
+	}
+}
+
 func TestListBoards(t *testing.T) {
 	assert := assert.New(t)
 	TempTransaction(func (p app.BoardCrudRepository, tx *gorm.DB) {
@@ -84,6 +202,42 @@ func TestListBoards(t *testing.T) {
 	})
 }
 
+func TestSearchBoards(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(p app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		for _, name := range []string{"Search Alpha", "Search Beta", "search gamma", "Search 100% Delta", "Elsewhere"} {
+			board := app.Board{Name: name, Width: 10, Height: 20}
+			if err := p.CreateBoard(ctx, &board); err != nil {
+				t.Fatalf("CreateBoard failed: %+v", err)
+			}
+		}
+
+		results, total, err := p.SearchBoards(ctx, app.BoardQuery{Name: "SEARCH", Sort: app.BoardSortName, Descending: true, Limit: 2})
+		if err != nil {
+			t.Fatalf("SearchBoards returned error: %+v", err)
+		}
+		assert.ThatInt(total).IsEqualTo(4)
+		assert.ThatInt(len(results)).IsEqualTo(2)
+		assert.ThatString(results[0].Name).IsEqualTo("search gamma")
+		assert.ThatString(results[1].Name).IsEqualTo("Search Beta")
+
+		results, _, err = p.SearchBoards(ctx, app.BoardQuery{Name: "search", Sort: app.BoardSortCreated, Offset: 3, Limit: 2})
+		if err != nil {
+			t.Fatalf("SearchBoards returned error: %+v", err)
+		}
+		assert.ThatInt(len(results)).IsEqualTo(1)
+		assert.ThatString(results[0].Name).IsEqualTo("Search 100% Delta")
+
+		results, total, err = p.SearchBoards(ctx, app.BoardQuery{Name: "0%", Limit: 10})
+		if err != nil {
+			t.Fatalf("SearchBoards returned error: %+v", err)
+		}
+		assert.ThatInt(total).IsEqualTo(1)
+		assert.ThatString(results[0].Name).IsEqualTo("Search 100% Delta")
+	})
+}
+
 func TestCreateBoard(t *testing.T) {
 	assert := assert.New(t)
 	TempTransaction(func(p app.BoardCrudRepository, tx *gorm.DB) {
@@ -188,6 +342,7 @@ func TestUpdateBoard(t *testing.T) {
 		assert.ThatString(board.Name).IsEqualTo("New Name")
 		assert.ThatInt(board.Width).IsEqualTo(123)
 		assert.ThatInt(board.Height).IsEqualTo(321)
+		assert.ThatInt(board.Version).IsEqualTo(2)
 
 
 		dupe := app.Board{
@@ -263,6 +418,87 @@ func TestDeleteBoardByIDDeletesNestedRecords(t *testing.T) {
 	})
 }
 
+func TestGetBoardDeleteImpactAndDeleteBoardInUse(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+
+		_, err := r.GetBoardDeleteImpact(ctx, 1234)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("did not receive RecordNotFound error when board didn't exist, got: %+v", err)
+		}
+
+		board := createTestBoard(tx)
+		createTestCityWithSpaces(tx, board.ID)
+		createTestRouteWithSpaces(tx, board.ID)
+		version := app.BoardVersion{BoardID: board.ID}
+		if err = r.CreateBoardVersion(ctx, &version); err != nil {
+			t.Fatalf("CreateBoardVersion returned error: %+v", err)
+		}
+
+		impact, err := r.GetBoardDeleteImpact(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardDeleteImpact returned error: %+v", err)
+		}
+		assert.That(*impact).IsEqualTo(app.BoardDeleteImpact{
+			BoardID:     board.ID,
+			Cities:      3,
+			CitySpaces:  3,
+			Routes:      1,
+			RouteSpaces: 3,
+			Versions:    1,
+			Deletable:   true,
+		})
+
+		game := Game{Name: "Test Game", BoardVersionID: &version.ID}
+		if err = tx.Create(&game).Error; err != nil {
+			t.Fatalf("Creating game returned error: %+v", err)
+		}
+
+		impact, err = r.GetBoardDeleteImpact(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardDeleteImpact returned error: %+v", err)
+		}
+		assert.ThatInt(impact.Games).IsEqualTo(1)
+		assert.ThatBool(impact.Deletable).IsFalse()
+
+		err = r.DeleteBoardByID(ctx, board.ID)
+		if !errors.Is(app.BoardInUse{}, err) {
+			t.Fatalf("expected BoardInUse error, got: %+v", err)
+		}
+		_, err = r.GetBoardByID(ctx, board.ID)
+		assert.That(err).IsNil()
+	})
+}
+
+func TestGetBoardGraphByID(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+
+		_, err := r.GetBoardGraphByID(ctx, 1234)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("did not receive RecordNotFound error when board didn't exist, got: %+v", err)
+		}
+
+		board := createTestBoard(tx)
+		createTestCityWithSpaces(tx, board.ID)
+		createTestRouteWithSpaces(tx, board.ID)
+
+		graph, err := r.GetBoardGraphByID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardGraphByID returned error: %+v", err)
+		}
+
+		assert.ThatString(graph.Name).IsEqualTo(board.Name)
+		assert.ThatInt(len(graph.Cities)).IsEqualTo(3)
+		assert.ThatInt(len(graph.Cities[0].CitySpaces)).IsEqualTo(3)
+		assert.ThatInt(graph.Cities[0].CitySpaces[2].Order).IsEqualTo(3)
+		assert.ThatInt(len(graph.Routes)).IsEqualTo(1)
+		assert.ThatInt(len(graph.Routes[0].RouteSpaces)).IsEqualTo(3)
+	})
+}
+
 func TestListCitiesByBoardId(t *testing.T) {
 	assert := assert.New(t)
 	TempTransaction(func (r app.BoardCrudRepository, tx *gorm.DB) {
@@ -318,6 +554,9 @@ func TestCreateCity(t *testing.T) {
 		if updatedCity.Position.Y != 432 {
 			t.Error("City Position Y was not updated")
 		}
+		if city.Version != 1 || updatedCity.Version != 1 {
+			t.Errorf("New city should start at Version 1 (was %d)", updatedCity.Version)
+		}
 	})
 }
 
@@ -352,6 +591,9 @@ func TestUpdateCity(t *testing.T) {
 		if updatedCity.Position.Y != 432 {
 			t.Error("City Position Y was not updated")
 		}
+		if updatedCity.Version != city.Version+1 {
+			t.Errorf("City Version was not incremented (was %d)", updatedCity.Version)
+		}
 	})
 }
 
@@ -439,6 +681,693 @@ func TestDeleteCitySpaceByIDSucceeds(t *testing.T) {
 	})
 }
 
+func TestCreateRoute(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		start := createTestCity(tx, board.ID)
+		end := createTestCity(tx, board.ID)
+
+		route := app.Route{
+			BoardID:     board.ID,
+			StartCityID: start.ID,
+			EndCityID:   end.ID,
+		}
+		err := r.CreateRoute(ctx, &route)
+		if err != nil {
+			t.Fatalf("CreateRoute returned error: %+v", err)
+		}
+		assert.ThatUint64(uint64(route.ID)).IsNonZero()
+
+		saved, err := r.GetRouteByID(ctx, route.ID)
+		if err != nil {
+			t.Fatalf("GetRouteByID returned error: %+v", err)
+		}
+		assert.That(saved.BoardID).IsEqualTo(board.ID)
+		assert.That(saved.StartCityID).IsEqualTo(start.ID)
+		assert.That(saved.EndCityID).IsEqualTo(end.ID)
+	})
+}
+
+func TestCreateRouteRejectsInvalidCities(t *testing.T) {
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		otherBoard := createTestBoard(tx)
+		city := createTestCity(tx, board.ID)
+		otherCity := createTestCity(tx, otherBoard.ID)
+
+		selfLoop := app.Route{
+			BoardID:     board.ID,
+			StartCityID: city.ID,
+			EndCityID:   city.ID,
+		}
+		if err := r.CreateRoute(ctx, &selfLoop); err == nil {
+			t.Error("CreateRoute should have rejected a route from a city to itself")
+		}
+
+		crossBoard := app.Route{
+			BoardID:     board.ID,
+			StartCityID: city.ID,
+			EndCityID:   otherCity.ID,
+		}
+		if err := r.CreateRoute(ctx, &crossBoard); err == nil {
+			t.Error("CreateRoute should have rejected a route between cities on different boards")
+		}
+	})
+}
+
+func TestListRoutesByBoardID(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+
+		_, err := r.ListRoutesByBoardID(ctx, 1234)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("did not receive RecordNotFound error when board didn't exist, got: %+v", err)
+		}
+
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+
+		results, err := r.ListRoutesByBoardID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("ListRoutesByBoardID returned error: %+v", err)
+		}
+		assert.ThatInt(len(results)).IsEqualTo(1)
+		assert.ThatInt(len(results[0].RouteSpaces)).IsEqualTo(len(route.RouteSpaces))
+		assert.ThatInt(results[0].RouteSpaces[0].Order).IsEqualTo(1)
+	})
+}
+
+func TestUpdateRoute(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+		newEnd := createTestCity(tx, board.ID)
+
+		_, err := r.UpdateRoute(ctx, route.ID, func(route *app.Route) (*app.Route, error) {
+			route.EndCityID = newEnd.ID
+			return route, nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateRoute returned error: %+v", err)
+		}
+
+		updatedRoute, err := r.GetRouteByID(ctx, route.ID)
+		if err != nil {
+			t.Fatalf("error reloading route: %+v", err)
+		}
+		assert.That(updatedRoute.EndCityID).IsEqualTo(newEnd.ID)
+		assert.ThatInt(len(updatedRoute.RouteSpaces)).IsEqualTo(len(route.RouteSpaces))
+	})
+}
+
+func TestDeleteRouteByIDDeletesSpaces(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+
+		err := r.DeleteRouteByID(ctx, route.ID)
+		assert.That(err).IsNil()
+
+		_, err = r.GetRouteByID(ctx, route.ID)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound after deleting route, got: %+v", err)
+		}
+
+		var spaces []RouteSpace
+		err = tx.Find(&spaces, "route_id = ?", route.ID).Error
+		assert.That(err).IsNil()
+		assert.ThatInt(len(spaces)).IsEqualTo(0)
+	})
+}
+
+func TestUpdateRouteSpacesReordersAddsAndRemoves(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+		first, second, third := route.RouteSpaces[0], route.RouteSpaces[1], route.RouteSpaces[2]
+
+		updatedRoute, err := r.UpdateRouteSpaces(ctx, route.ID, func(route *app.Route) (*app.Route, error) {
+			route.TavernFlag = true
+			route.RouteSpaces = []app.RouteSpace{
+				{Model: app.Model{ID: third.ID}, RouteID: route.ID, Order: 1},
+				{RouteID: route.ID, Order: 2},
+				{Model: app.Model{ID: first.ID}, RouteID: route.ID, Order: 3},
+			}
+			return route, nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateRouteSpaces returned error: %+v", err)
+		}
+
+		assert.ThatBool(updatedRoute.TavernFlag).IsTrue()
+		assert.ThatInt(len(updatedRoute.RouteSpaces)).IsEqualTo(3)
+		assert.That(updatedRoute.RouteSpaces[0].ID).IsEqualTo(third.ID)
+		assert.ThatInt(updatedRoute.RouteSpaces[0].Order).IsEqualTo(1)
+		assert.ThatUint64(uint64(updatedRoute.RouteSpaces[1].ID)).IsNonZero()
+		assert.ThatInt(updatedRoute.RouteSpaces[1].Order).IsEqualTo(2)
+		assert.That(updatedRoute.RouteSpaces[2].ID).IsEqualTo(first.ID)
+		assert.ThatInt(updatedRoute.RouteSpaces[2].Order).IsEqualTo(3)
+
+		var removed []RouteSpace
+		err = tx.Find(&removed, second.ID).Error
+		assert.That(err).IsNil()
+		assert.ThatInt(len(removed)).IsEqualTo(0)
+	})
+}
+
+func TestUpdateRouteSpacesRejectsForeignSpaces(t *testing.T) {
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+		otherRoute := createTestRouteWithSpaces(tx, board.ID)
+
+		_, err := r.UpdateRouteSpaces(ctx, route.ID, func(route *app.Route) (*app.Route, error) {
+			route.RouteSpaces = []app.RouteSpace{
+				{Model: app.Model{ID: otherRoute.RouteSpaces[0].ID}, RouteID: route.ID, Order: 1},
+			}
+			return route, nil
+		})
+		if err == nil {
+			t.Error("UpdateRouteSpaces should have rejected a space belonging to another route")
+		}
+	})
+}
+
+func TestUpdateCitySpace(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func (r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		city := createTestCityWithSpaces(tx, board.ID)
+		spaceID := city.CitySpaces[0].ID
+
+		_, err := r.UpdateCitySpace(ctx, spaceID, func(space *app.CitySpace) (*app.CitySpace, error) {
+			space.SpaceType = app.MerchantID
+			space.RequiredPrivilege = 4
+			return space, nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateCitySpace returned error: %+v", err)
+		}
+
+		var space CitySpace
+		assert.That(tx.First(&space, spaceID).Error).IsNil()
+		assert.That(space.SpaceType).IsEqualTo(app.MerchantID)
+		assert.ThatInt(space.RequiredPrivilege).IsEqualTo(4)
+		assert.ThatInt(space.Order).IsEqualTo(1)
+
+		_, err = r.UpdateCitySpace(ctx, 9999, func(space *app.CitySpace) (*app.CitySpace, error) {
+			return space, nil
+		})
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound for missing space, got: %+v", err)
+		}
+	})
+}
+
+func TestReorderCitySpaces(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func (r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		city := createTestCityWithSpaces(tx, board.ID)
+		first, second, third := city.CitySpaces[0].ID, city.CitySpaces[1].ID, city.CitySpaces[2].ID
+
+		spaces, err := r.ReorderCitySpaces(ctx, city.ID, []app.ID{third, first, second})
+		if err != nil {
+			t.Fatalf("ReorderCitySpaces returned error: %+v", err)
+		}
+		assert.ThatInt(len(spaces)).IsEqualTo(3)
+		assert.That(spaces[0].ID).IsEqualTo(third)
+		assert.That(spaces[1].ID).IsEqualTo(first)
+		assert.That(spaces[2].ID).IsEqualTo(second)
+		assert.ThatInt(spaces[2].Order).IsEqualTo(3)
+
+		_, err = r.ReorderCitySpaces(ctx, city.ID, []app.ID{third, first})
+		if err == nil {
+			t.Error("ReorderCitySpaces should have rejected an ordering missing a space")
+		}
+	})
+}
+
+func TestCreateBoardGraph(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+
+		board := app.Board{
+			Name:        "Imported Board",
+			Width:       100,
+			Height:      100,
+			PlayerRange: app.PlayerRange{MinPlayers: 2, MaxPlayers: 5},
+			Cities: []app.City{
+				{
+					Model:   app.Model{ID: 1},
+					Name:    "First",
+					Upgrade: app.AbilityMove,
+					CitySpaces: []app.CitySpace{
+						{Order: 1, SpaceType: app.TraderID, RequiredPrivilege: 1},
+						{Order: 2, SpaceType: app.MerchantID, RequiredPrivilege: 2},
+					},
+				},
+				{
+					Model:       app.Model{ID: 2},
+					Name:        "Second",
+					PlayerRange: app.PlayerRange{MinPlayers: 4},
+				},
+			},
+			Routes: []app.Route{
+				{
+					StartCityID: 1,
+					EndCityID:   2,
+					TavernFlag:  true,
+					PlayerRange: app.PlayerRange{MaxPlayers: 3},
+					RouteSpaces: []app.RouteSpace{{Order: 1}, {Order: 2}},
+
+					StartingTokenOrder: 1,
+				},
+			},
+			ConnectionObjective: &app.ConnectionObjective{StartCityID: 1, EndCityID: 2, Awards: []int{7}},
+			PrestigeTable: &app.PrestigeTable{
+				CityID: 2,
+				Slots:  []app.PrestigeSlot{{Order: 1, RequiredPrivilege: 1, Points: 7}},
+			},
+		}
+
+		if err := r.CreateBoardGraph(ctx, &board); err != nil {
+			t.Fatalf("CreateBoardGraph returned error: %+v", err)
+		}
+
+		assert.ThatUint64(uint64(board.ID)).IsNonZero()
+		assert.ThatInt(len(board.Cities)).IsEqualTo(2)
+		assert.ThatInt(len(board.Cities[0].CitySpaces)).IsEqualTo(2)
+		assert.ThatInt(len(board.Routes)).IsEqualTo(1)
+		assert.That(board.Routes[0].StartCityID).IsEqualTo(board.Cities[0].ID)
+		assert.That(board.Routes[0].EndCityID).IsEqualTo(board.Cities[1].ID)
+		assert.ThatBool(board.Routes[0].TavernFlag).IsTrue()
+		assert.ThatInt(len(board.Routes[0].RouteSpaces)).IsEqualTo(2)
+		assert.That(board.PlayerRange).IsEqualTo(app.PlayerRange{MinPlayers: 2, MaxPlayers: 5})
+		assert.That(board.Cities[1].PlayerRange).IsEqualTo(app.PlayerRange{MinPlayers: 4})
+		assert.That(board.Cities[0].Upgrade).IsEqualTo(app.AbilityMove)
+		assert.That(board.PrestigeTable.CityID).IsEqualTo(board.Cities[1].ID)
+		assert.That(board.ConnectionObjective.StartCityID).IsEqualTo(board.Cities[0].ID)
+		assert.That(board.ConnectionObjective.EndCityID).IsEqualTo(board.Cities[1].ID)
+		assert.ThatInt(len(board.PrestigeTable.Slots)).IsEqualTo(1)
+		assert.That(board.Routes[0].PlayerRange).IsEqualTo(app.PlayerRange{MaxPlayers: 3})
+		assert.ThatInt(board.Routes[0].StartingTokenOrder).IsEqualTo(1)
+
+		duplicate := app.Board{Name: "Imported Board"}
+		err := r.CreateBoardGraph(ctx, &duplicate)
+		if !errors.Is(err, app.ErrNameTaken) {
+			t.Errorf("expected ErrNameTaken for a duplicate name, got: %+v", err)
+		}
+	})
+}
+
+func TestBoardVersions(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		createTestRouteWithSpaces(tx, board.ID)
+
+		graph, err := r.GetBoardGraphByID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardGraphByID returned error: %+v", err)
+		}
+
+		first := app.BoardVersion{BoardID: board.ID, Snapshot: app.NewBoardDocument(graph)}
+		if err = r.CreateBoardVersion(ctx, &first); err != nil {
+			t.Fatalf("CreateBoardVersion returned error: %+v", err)
+		}
+		second := app.BoardVersion{BoardID: board.ID, Snapshot: app.NewBoardDocument(graph)}
+		if err = r.CreateBoardVersion(ctx, &second); err != nil {
+			t.Fatalf("CreateBoardVersion returned error: %+v", err)
+		}
+
+		assert.ThatUint64(uint64(first.ID)).IsNonZero()
+		assert.ThatInt(first.Number).IsEqualTo(1)
+		assert.ThatInt(second.Number).IsEqualTo(2)
+
+		versions, err := r.ListBoardVersionsByBoardID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("ListBoardVersionsByBoardID returned error: %+v", err)
+		}
+		assert.ThatInt(len(versions)).IsEqualTo(2)
+		assert.ThatInt(versions[1].Number).IsEqualTo(2)
+
+		version, err := r.GetBoardVersionByNumber(ctx, board.ID, 1)
+		if err != nil {
+			t.Fatalf("GetBoardVersionByNumber returned error: %+v", err)
+		}
+		assert.ThatInt(len(version.Snapshot.Cities)).IsEqualTo(2)
+		assert.ThatInt(len(version.Snapshot.Routes)).IsEqualTo(1)
+		assert.ThatInt(version.Snapshot.Routes[0].Spaces).IsEqualTo(3)
+
+		_, err = r.GetBoardVersionByNumber(ctx, board.ID, 3)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound for missing version, got: %+v", err)
+		}
+
+		err = tx.Model(&BoardVersion{Model: Model{ID: first.ID}}).Update("number", 5).Error
+		if err == nil {
+			t.Error("published versions should not be updatable")
+		}
+
+		err = r.CreateBoardVersion(ctx, &app.BoardVersion{BoardID: 1234})
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound when board didn't exist, got: %+v", err)
+		}
+	})
+}
+
+func TestRestoreCity(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+
+		city, err := r.GetCityByID(ctx, route.StartCityID)
+		if err != nil {
+			t.Fatalf("GetCityByID returned error: %+v", err)
+		}
+		city.CitySpaces = []app.CitySpace{
+			{Model: app.Model{ID: 9001}, CityID: city.ID, Order: 1, SpaceType: app.MerchantID, RequiredPrivilege: 2},
+		}
+		routes, err := r.ListRoutesByBoardID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("ListRoutesByBoardID returned error: %+v", err)
+		}
+
+		if err = r.DeleteCityByID(ctx, city.ID); err != nil {
+			t.Fatalf("DeleteCityByID returned error: %+v", err)
+		}
+
+		if err = r.RestoreCity(ctx, city, routes); err != nil {
+			t.Fatalf("RestoreCity returned error: %+v", err)
+		}
+
+		restored, err := r.GetCityByID(ctx, city.ID)
+		if err != nil {
+			t.Fatalf("GetCityByID returned error: %+v", err)
+		}
+		assert.ThatString(restored.Name).IsEqualTo(city.Name)
+		assert.ThatInt(len(restored.CitySpaces)).IsEqualTo(1)
+		assert.That(restored.CitySpaces[0].ID).IsEqualTo(ID(9001))
+
+		restoredRoute, err := r.GetRouteByID(ctx, route.ID)
+		if err != nil {
+			t.Fatalf("GetRouteByID returned error: %+v", err)
+		}
+		assert.ThatInt(len(restoredRoute.RouteSpaces)).IsEqualTo(3)
+		assert.That(restoredRoute.RouteSpaces[0].ID).IsEqualTo(route.RouteSpaces[0].ID)
+	})
+}
+
+func TestRestoreRoute(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		created := createTestRouteWithSpaces(tx, board.ID)
+
+		route, err := r.GetRouteByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("GetRouteByID returned error: %+v", err)
+		}
+
+		// Restoring a route that still exists puts back its fields and exactly its spaces
+		changed := *route
+		changed.TavernFlag = true
+		changed.RouteSpaces = route.RouteSpaces[:2]
+		if err = r.RestoreRoute(ctx, &changed); err != nil {
+			t.Fatalf("RestoreRoute returned error: %+v", err)
+		}
+		restored, err := r.GetRouteByID(ctx, route.ID)
+		if err != nil {
+			t.Fatalf("GetRouteByID returned error: %+v", err)
+		}
+		assert.That(restored.TavernFlag).IsEqualTo(true)
+		assert.ThatInt(len(restored.RouteSpaces)).IsEqualTo(2)
+
+		// Restoring a deleted route creates it again under the same IDs
+		if err = r.DeleteRouteByID(ctx, route.ID); err != nil {
+			t.Fatalf("DeleteRouteByID returned error: %+v", err)
+		}
+		if err = r.RestoreRoute(ctx, route); err != nil {
+			t.Fatalf("RestoreRoute returned error: %+v", err)
+		}
+		restored, err = r.GetRouteByID(ctx, route.ID)
+		if err != nil {
+			t.Fatalf("GetRouteByID returned error: %+v", err)
+		}
+		assert.That(restored.TavernFlag).IsEqualTo(false)
+		assert.ThatInt(len(restored.RouteSpaces)).IsEqualTo(3)
+		assert.That(restored.RouteSpaces[2].ID).IsEqualTo(route.RouteSpaces[2].ID)
+	})
+}
+
+func TestRestoreCitySpaces(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		city := createTestCityWithSpaces(tx, board.ID)
+
+		spaces, err := r.GetCitySpacesByCityID(ctx, city.ID)
+		if err != nil {
+			t.Fatalf("GetCitySpacesByCityID returned error: %+v", err)
+		}
+
+		// Swap the last two spaces around and drop the first
+		restoredSpaces := []app.CitySpace{spaces[2], spaces[1]}
+		restoredSpaces[0].Order = 1
+		restoredSpaces[1].Order = 2
+		if err = r.RestoreCitySpaces(ctx, city.ID, restoredSpaces); err != nil {
+			t.Fatalf("RestoreCitySpaces returned error: %+v", err)
+		}
+
+		restored, err := r.GetCitySpacesByCityID(ctx, city.ID)
+		if err != nil {
+			t.Fatalf("GetCitySpacesByCityID returned error: %+v", err)
+		}
+		assert.ThatInt(len(restored)).IsEqualTo(2)
+		assert.That(restored[0].ID).IsEqualTo(spaces[2].ID)
+		assert.That(restored[1].ID).IsEqualTo(spaces[1].ID)
+
+		err = r.RestoreCitySpaces(ctx, 9999, nil)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("RestoreCitySpaces for a missing city should return RecordNotFound, was: %+v", err)
+		}
+	})
+}
+
+func TestPrestigeTable(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+
+		_, err := r.GetPrestigeTableByBoardID(ctx, board.ID)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound before the table was saved, got: %+v", err)
+		}
+
+		table := app.PrestigeTable{
+			BoardID: board.ID,
+			CityID:  route.StartCityID,
+			Slots: []app.PrestigeSlot{
+				{Order: 1, RequiredPrivilege: 1, Points: 7},
+				{Order: 2, RequiredPrivilege: 2, Points: 8},
+			},
+		}
+		if err = r.SavePrestigeTable(ctx, &table); err != nil {
+			t.Fatalf("SavePrestigeTable returned error: %+v", err)
+		}
+		assert.ThatUint64(uint64(table.ID)).IsNonZero()
+
+		// Saving again replaces the slots and moves the table
+		replacement := app.PrestigeTable{
+			BoardID: board.ID,
+			CityID:  route.EndCityID,
+			Slots:   []app.PrestigeSlot{{Order: 1, RequiredPrivilege: 3, Points: 11}},
+		}
+		if err = r.SavePrestigeTable(ctx, &replacement); err != nil {
+			t.Fatalf("SavePrestigeTable returned error: %+v", err)
+		}
+		assert.That(replacement.ID).IsEqualTo(table.ID)
+
+		graph, err := r.GetBoardGraphByID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardGraphByID returned error: %+v", err)
+		}
+		assert.That(graph.PrestigeTable.CityID).IsEqualTo(route.EndCityID)
+		assert.ThatInt(len(graph.PrestigeTable.Slots)).IsEqualTo(1)
+		assert.ThatInt(graph.PrestigeTable.Slots[0].Points).IsEqualTo(11)
+
+		other := createTestBoard(tx)
+		err = r.SavePrestigeTable(ctx, &app.PrestigeTable{BoardID: other.ID, CityID: route.EndCityID})
+		if err == nil {
+			t.Error("expected an error for a city on another board")
+		}
+
+		// Deleting the host city takes the table with it
+		if err = r.DeleteCityByID(ctx, route.EndCityID); err != nil {
+			t.Fatalf("DeleteCityByID returned error: %+v", err)
+		}
+		_, err = r.GetPrestigeTableByBoardID(ctx, board.ID)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound after the city was deleted, got: %+v", err)
+		}
+		var slots int64
+		tx.Model(&PrestigeSlot{}).Where("prestige_table_id = ?", table.ID).Count(&slots)
+		assert.ThatInt(int(slots)).IsEqualTo(0)
+	})
+}
+
+func TestConnectionObjective(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+
+		_, err := r.UpdateBoard(ctx, board.ID, func(board *app.Board) (*app.Board, error) {
+			board.ConnectionObjective = &app.ConnectionObjective{
+				StartCityID: route.StartCityID,
+				EndCityID:   route.EndCityID,
+				Awards:      []int{7, 4, 2},
+			}
+			return board, nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateBoard returned error: %+v", err)
+		}
+
+		found, err := r.GetBoardByID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardByID returned error: %+v", err)
+		}
+		assert.That(found.ConnectionObjective.StartCityID).IsEqualTo(route.StartCityID)
+		assert.That(found.ConnectionObjective.Awards).IsEqualTo([]int{7, 4, 2})
+		version := found.Version
+
+		// Deleting either city removes the objective, which makes a new version of the board
+		if err = r.DeleteCityByID(ctx, route.EndCityID); err != nil {
+			t.Fatalf("DeleteCityByID returned error: %+v", err)
+		}
+		found, err = r.GetBoardByID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardByID returned error: %+v", err)
+		}
+		if found.ConnectionObjective != nil {
+			t.Errorf("expected no connection objective, got %+v", found.ConnectionObjective)
+		}
+		assert.ThatInt(found.Version).IsEqualTo(version + 1)
+
+		// Deleting a city that isn't part of an objective leaves the board alone
+		if err = r.DeleteCityByID(ctx, route.StartCityID); err != nil {
+			t.Fatalf("DeleteCityByID returned error: %+v", err)
+		}
+		found, err = r.GetBoardByID(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetBoardByID returned error: %+v", err)
+		}
+		assert.ThatInt(found.Version).IsEqualTo(version + 1)
+	})
+}
+
+func TestRouteStartingTokenOrder(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+		route := createTestRouteWithSpaces(tx, board.ID)
+
+		_, err := r.UpdateRoute(ctx, route.ID, func(route *app.Route) (*app.Route, error) {
+			route.TavernFlag = true
+			route.StartingTokenOrder = 2
+			return route, nil
+		})
+		if err != nil {
+			t.Fatalf("UpdateRoute returned error: %+v", err)
+		}
+
+		found, err := r.GetRouteByID(ctx, route.ID)
+		if err != nil {
+			t.Fatalf("GetRouteByID returned error: %+v", err)
+		}
+		assert.ThatInt(found.StartingTokenOrder).IsEqualTo(2)
+	})
+}
+
+func TestBoardCommands(t *testing.T) {
+	assert := assert.New(t)
+	TempTransaction(func(r app.BoardCrudRepository, tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		board := createTestBoard(tx)
+
+		_, err := r.GetLastBoardCommand(ctx, board.ID)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected RecordNotFound with an empty history, got: %+v", err)
+		}
+
+		newCommand := func(name string) *app.BoardCommand {
+			command := app.BoardCommand{
+				BoardID: board.ID,
+				Kind:    app.CommandUpdateBoard,
+				Before:  app.BoardCommandState{Board: &app.Board{Name: board.Name}},
+				After:   app.BoardCommandState{Board: &app.Board{Name: name}},
+			}
+			if err := r.CreateBoardCommand(ctx, &command); err != nil {
+				t.Fatalf("CreateBoardCommand returned error: %+v", err)
+			}
+			return &command
+		}
+		first := newCommand("First")
+		second := newCommand("Second")
+
+		last, err := r.GetLastBoardCommand(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetLastBoardCommand returned error: %+v", err)
+		}
+		assert.That(last.ID).IsEqualTo(second.ID)
+		assert.ThatString(last.After.Board.Name).IsEqualTo("Second")
+
+		assert.That(r.SetBoardCommandUndone(ctx, second.ID, true)).IsNil()
+		assert.That(r.SetBoardCommandUndone(ctx, first.ID, true)).IsNil()
+
+		undone, err := r.GetLastUndoneBoardCommand(ctx, board.ID)
+		if err != nil {
+			t.Fatalf("GetLastUndoneBoardCommand returned error: %+v", err)
+		}
+		assert.That(undone.ID).IsEqualTo(first.ID)
+
+		// A new command discards everything that was undone
+		third := newCommand("Third")
+		_, err = r.GetLastUndoneBoardCommand(ctx, board.ID)
+		if !errors.Is(app.RecordNotFound{}, err) {
+			t.Errorf("expected undone commands to be discarded, got: %+v", err)
+		}
+		last, err = r.GetLastBoardCommand(ctx, board.ID)
+		assert.That(err).IsNil()
+		assert.That(last.ID).IsEqualTo(third.ID)
+	})
+}
+
 var testBoardCounter = 0
 
 func createTestBoard(tx *gorm.DB) *Board {
@@ -497,3 +1426,28 @@ func createTestCityWithSpaces(tx *gorm.DB, boardID ID) *City {
 
 	return city
 }
+
+func createTestRouteWithSpaces(tx *gorm.DB, boardID ID) *Route {
+	start := createTestCity(tx, boardID)
+	end := createTestCity(tx, boardID)
+
+	route := Route{
+		BoardID:     boardID,
+		StartCityID: start.ID,
+		EndCityID:   end.ID,
+	}
+	if err := tx.Save(&route).Error; err != nil {
+		panic(err)
+	}
+
+	route.RouteSpaces = []RouteSpace{
+		{RouteID: route.ID, Order: 1},
+		{RouteID: route.ID, Order: 2},
+		{RouteID: route.ID, Order: 3},
+	}
+	if err := tx.Save(route.RouteSpaces).Error; err != nil {
+		panic(err)
+	}
+
+	return &route
+}
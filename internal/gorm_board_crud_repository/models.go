@@ -2,6 +2,9 @@ package gorm_board_crud_repository
 
 import (
 	"city-route-game/internal/app"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"gorm.io/gorm"
 	"time"
@@ -12,6 +15,8 @@ func Models() []interface{} {
 	return []interface{}{
 		&Game{},
 		&Board{},
+		&BoardVersion{},
+		&BoardCommand{},
 		&Player{},
 		&PlayerBoard{},
 		&PlayerBonusToken{},
@@ -21,9 +26,109 @@ func Models() []interface{} {
 		&CitySpace{},
 		&Route{},
 		&RouteSpace{},
+		&PrestigeTable{},
+		&PrestigeSlot{},
+		&PrestigeSlotClaim{},
 	}
 }
 
+// Migrate Bring the database up to date with the models. AutoMigrate never drops anything, so data that has moved
+// elsewhere is moved here before the old columns are dropped.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(Models()...); err != nil {
+		return err
+	}
+
+	if err := migrateBoardGameLinks(db); err != nil {
+		return err
+	}
+
+	return migrateCoellenClaims(db)
+}
+
+// migrateBoardGameLinks Boards used to point at the game played on them, and games now point at a published version
+// of their board instead. Each linked board is published as it is now, and its game is given that version.
+func migrateBoardGameLinks(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Board{}, "game_id") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var links []struct {
+			ID     ID
+			GameID ID
+		}
+		if err := tx.Table("boards").Select("id, game_id").Where("game_id IS NOT NULL").Scan(&links).Error; err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		repo := NewGormBoardCrudRepository(tx)
+		for _, link := range links {
+			board, err := repo.GetBoardGraphByID(ctx, link.ID)
+			if err != nil {
+				return err
+			}
+
+			version := app.BoardVersion{
+				BoardID:  board.ID,
+				Snapshot: app.NewBoardDocument(board),
+			}
+			if err = repo.CreateBoardVersion(ctx, &version); err != nil {
+				return err
+			}
+
+			err = tx.Model(&Game{}).
+				Where("id = ? AND board_version_id IS NULL", link.GameID).
+				Update("board_version_id", version.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Migrator().DropColumn(&Board{}, "game_id")
+	})
+}
+
+// migrateCoellenClaims Games used to hold a column per slot of the Coellen table, which are now the slots of the
+// prestige table in the same order
+func migrateCoellenClaims(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Game{}, "coellen1_player_id") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for order := 1; order <= 4; order++ {
+			column := fmt.Sprintf("coellen%d_player_id", order)
+
+			var claims []struct {
+				ID       ID
+				PlayerID ID
+			}
+			err := tx.Table("games").
+				Select("id, " + column + " AS player_id").
+				Where(column + " IS NOT NULL").
+				Scan(&claims).Error
+			if err != nil {
+				return err
+			}
+
+			for _, claim := range claims {
+				err = tx.Create(&PrestigeSlotClaim{GameID: claim.ID, SlotOrder: order, PlayerID: claim.PlayerID}).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			if err = tx.Migrator().DropColumn(&Game{}, column); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 type constraintViolation struct {
 	msg string
 }
@@ -48,14 +153,84 @@ type Position struct {
 	Y int `json:"y" gorm:"not null;default:0"`
 }
 
+// PlayerRange is a shared mixin with the numbers of players something is in play for
+type PlayerRange struct {
+	MinPlayers int `json:"minPlayers" gorm:"not null;default:0"`
+	MaxPlayers int `json:"maxPlayers" gorm:"not null;default:0"`
+}
+
+// ConnectionObjective is a mixin for Board. It is stored with the board, and the board has none while the cities are null.
+type ConnectionObjective struct {
+	ConnectionStartCityID *ID     `json:"connectionStartCityId"`
+	ConnectionEndCityID   *ID     `json:"connectionEndCityId"`
+	ConnectionAwards      intList `json:"connectionAwards" gorm:"type:text"`
+}
+
+func newGormConnectionObjective(objective *app.ConnectionObjective) ConnectionObjective {
+	if objective == nil {
+		return ConnectionObjective{}
+	}
+
+	startCityID := objective.StartCityID
+	endCityID := objective.EndCityID
+	return ConnectionObjective{
+		ConnectionStartCityID: &startCityID,
+		ConnectionEndCityID:   &endCityID,
+		ConnectionAwards:      append(intList{}, objective.Awards...),
+	}
+}
+
+func newAppConnectionObjective(objective ConnectionObjective) *app.ConnectionObjective {
+	if objective.ConnectionStartCityID == nil || objective.ConnectionEndCityID == nil {
+		return nil
+	}
+
+	return &app.ConnectionObjective{
+		StartCityID: *objective.ConnectionStartCityID,
+		EndCityID:   *objective.ConnectionEndCityID,
+		Awards:      append([]int{}, objective.ConnectionAwards...),
+	}
+}
+
+// intList A list of numbers stored in a single column as a JSON array
+type intList []int
+
+func (l intList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal([]int(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func (l *intList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), (*[]int)(l))
+	case []byte:
+		return json.Unmarshal(v, (*[]int)(l))
+	}
+	return fmt.Errorf("cannot scan %T into a list of numbers", value)
+}
+
 // Board structure base model
 type Board struct {
 	Model
 	Name   string `json:"name" gorm:"not null;uniqueIndex"`
-	GameID *ID  `json:"gameId" gorm:"index"`
 	Width  int    `json:"width" gorm:"not null;default:0"`
 	Height int    `json:"height" gorm:"not null;default:0"`
+	Version int   `json:"version" gorm:"not null;default:1"`
+	PlayerRange
+	ConnectionObjective
 	Cities []City `json:"cities"`
+	Routes []Route `json:"routes"`
+	PrestigeTable *PrestigeTable `json:"prestigeTable"`
 }
 
 func newGormBoardFromDomainBoard(board *app.Board) (*Board, error) {
@@ -72,6 +247,12 @@ func newGormBoardFromDomainBoard(board *app.Board) (*Board, error) {
 		Name: board.Name,
 		Width: board.Width,
 		Height: board.Height,
+		Version: board.Version,
+		PlayerRange: PlayerRange{
+			MinPlayers: board.MinPlayers,
+			MaxPlayers: board.MaxPlayers,
+		},
+		ConnectionObjective: newGormConnectionObjective(board.ConnectionObjective),
 	}, nil
 }
 
@@ -89,7 +270,41 @@ func newDomainBoardFromGormBoard(gormBoard *Board) *app.Board {
 		Name: gormBoard.Name,
 		Width: gormBoard.Width,
 		Height: gormBoard.Height,
+		Version: gormBoard.Version,
+		PlayerRange: app.PlayerRange{
+			MinPlayers: gormBoard.MinPlayers,
+			MaxPlayers: gormBoard.MaxPlayers,
+		},
+		ConnectionObjective: newAppConnectionObjective(gormBoard.ConnectionObjective),
+	}
+}
+
+// newDomainBoardGraphFromGormBoard Convert a board along with whatever cities and routes were preloaded
+func newDomainBoardGraphFromGormBoard(gormBoard *Board) *app.Board {
+	board := newDomainBoardFromGormBoard(gormBoard)
+
+	board.Cities = make([]app.City, 0, len(gormBoard.Cities))
+	for _, city := range gormBoard.Cities {
+		board.Cities = append(board.Cities, *newAppCityFromGormCity(&city))
+	}
+
+	board.Routes = make([]app.Route, 0, len(gormBoard.Routes))
+	for _, route := range gormBoard.Routes {
+		board.Routes = append(board.Routes, *newAppRouteFromGormRoute(&route))
+	}
+
+	if gormBoard.PrestigeTable != nil {
+		board.PrestigeTable = newAppPrestigeTableFromGormPrestigeTable(gormBoard.PrestigeTable)
+	}
+
+	return board
+}
+
+func (b *Board)BeforeCreate(tx *gorm.DB) error {
+	if b.Version == 0 {
+		b.Version = 1
 	}
+	return nil
 }
 
 func (b *Board)BeforeDelete(tx *gorm.DB) error {
@@ -107,7 +322,133 @@ func (b *Board)BeforeDelete(tx *gorm.DB) error {
 		}
 	}
 
-	return nil
+	if err = tx.Where("board_id = ?", b.ID).Delete(&BoardCommand{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("board_id = ?", b.ID).Delete(&BoardVersion{}).Error
+}
+
+// BoardVersion A published snapshot of a board. The snapshot is stored as the JSON of an app.BoardDocument.
+type BoardVersion struct {
+	Model
+	BoardID  ID     `json:"boardId" gorm:"not null;uniqueIndex:uidx_board_version_board_id_number"`
+	Number   int    `json:"number" gorm:"not null;uniqueIndex:uidx_board_version_board_id_number"`
+	Snapshot string `json:"snapshot" gorm:"not null"`
+}
+
+// BeforeUpdate Published versions may be referenced by games, so they must never change
+func (v *BoardVersion) BeforeUpdate(tx *gorm.DB) error {
+	return &constraintViolation{
+		msg: fmt.Sprintf("constraint violation: board version %d is published and cannot be changed", v.ID),
+	}
+}
+
+func newGormBoardVersionFromAppBoardVersion(version *app.BoardVersion) (*BoardVersion, error) {
+	if version == nil {
+		panic("version must not be nil")
+	}
+
+	snapshot, err := json.Marshal(&version.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoardVersion{
+		Model: Model{
+			ID:        version.ID,
+			CreatedAt: version.CreatedAt,
+			UpdatedAt: version.UpdatedAt,
+		},
+		BoardID:  version.BoardID,
+		Number:   version.Number,
+		Snapshot: string(snapshot),
+	}, nil
+}
+
+func newAppBoardVersionFromGormBoardVersion(version *BoardVersion) (*app.BoardVersion, error) {
+	if version == nil {
+		panic("version must not be nil")
+	}
+
+	appVersion := app.BoardVersion{
+		Model: app.Model{
+			ID:        version.ID,
+			CreatedAt: version.CreatedAt,
+			UpdatedAt: version.UpdatedAt,
+		},
+		BoardID: version.BoardID,
+		Number:  version.Number,
+	}
+	if err := json.Unmarshal([]byte(version.Snapshot), &appVersion.Snapshot); err != nil {
+		return nil, err
+	}
+
+	return &appVersion, nil
+}
+
+// BoardCommand An entry in a board's undo history. The before and after states are stored as the JSON
+// of an app.BoardCommandState.
+type BoardCommand struct {
+	Model
+	BoardID ID     `json:"boardId" gorm:"not null;index"`
+	Kind    string `json:"kind" gorm:"not null"`
+	Before  string `json:"before" gorm:"not null"`
+	After   string `json:"after" gorm:"not null"`
+	Undone  bool   `json:"undone" gorm:"not null;default:false"`
+}
+
+func newGormBoardCommandFromAppBoardCommand(command *app.BoardCommand) (*BoardCommand, error) {
+	if command == nil {
+		panic("command must not be nil")
+	}
+
+	before, err := json.Marshal(&command.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := json.Marshal(&command.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoardCommand{
+		Model: Model{
+			ID:        command.ID,
+			CreatedAt: command.CreatedAt,
+			UpdatedAt: command.UpdatedAt,
+		},
+		BoardID: command.BoardID,
+		Kind:    string(command.Kind),
+		Before:  string(before),
+		After:   string(after),
+		Undone:  command.Undone,
+	}, nil
+}
+
+func newAppBoardCommandFromGormBoardCommand(command *BoardCommand) (*app.BoardCommand, error) {
+	if command == nil {
+		panic("command must not be nil")
+	}
+
+	appCommand := app.BoardCommand{
+		Model: app.Model{
+			ID:        command.ID,
+			CreatedAt: command.CreatedAt,
+			UpdatedAt: command.UpdatedAt,
+		},
+		BoardID: command.BoardID,
+		Kind:    app.BoardCommandKind(command.Kind),
+		Undone:  command.Undone,
+	}
+	if err := json.Unmarshal([]byte(command.Before), &appCommand.Before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(command.After), &appCommand.After); err != nil {
+		return nil, err
+	}
+
+	return &appCommand, nil
 }
 
 // City part of the Board structure
@@ -116,6 +457,9 @@ type City struct {
 	BoardID    ID   `json:"boardId" gorm:"not null;index"`
 	Name       string `json:"name" gorm:"not null"`
 	Position   `json:"position"`
+	Version    int    `json:"version" gorm:"not null;default:1"`
+	PlayerRange
+	Upgrade    string `json:"upgrade" gorm:"not null;default:''"`
 	CitySpaces []CitySpace `json:"spaces"`
 }
 
@@ -136,6 +480,12 @@ func newGormCityFromAppCity(appCity *app.City)(*City, error) {
 			X: appCity.Position.X,
 			Y: appCity.Position.Y,
 		},
+		Version: appCity.Version,
+		PlayerRange: PlayerRange{
+			MinPlayers: appCity.MinPlayers,
+			MaxPlayers: appCity.MaxPlayers,
+		},
+		Upgrade: string(appCity.Upgrade),
 		CitySpaces: nil,
 	}
 
@@ -170,6 +520,12 @@ func newAppCityFromGormCity(gormCity *City) *app.City {
 			X: gormCity.Position.X,
 			Y: gormCity.Position.Y,
 		},
+		Version: gormCity.Version,
+		PlayerRange: app.PlayerRange{
+			MinPlayers: gormCity.MinPlayers,
+			MaxPlayers: gormCity.MaxPlayers,
+		},
+		Upgrade: app.Ability(gormCity.Upgrade),
 		CitySpaces: nil,
 	}
 
@@ -200,12 +556,46 @@ func (c *City)BeforeSave(tx *gorm.DB) error {
 	return nil
 }
 
+func (c *City)BeforeCreate(tx *gorm.DB) error {
+	if c.Version == 0 {
+		c.Version = 1
+	}
+	return nil
+}
+
 func (c *City)BeforeDelete(tx *gorm.DB) error {
 	err := tx.Delete(&CitySpace{}, "city_id = ?", c.ID).Error
 	if err != nil {
 		return err
 	}
 
+	// Neither can a connection objective without both of its cities
+	result := tx.Model(&Board{}).
+		Where("id = ? AND (connection_start_city_id = ? OR connection_end_city_id = ?)", c.BoardID, c.ID, c.ID).
+		Select("ConnectionStartCityID", "ConnectionEndCityID", "ConnectionAwards").
+		Updates(&Board{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		// The board changed, so anyone holding the previous version must not save over it
+		err = tx.Model(&Board{}).Where("id = ?", c.BoardID).UpdateColumn("version", gorm.Expr("version + 1")).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// The prestige table can't be without the city hosting it
+	var tables []PrestigeTable
+	if err = tx.Find(&tables, "city_id = ?", c.ID).Error; err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if err = tx.Delete(&table).Error; err != nil {
+			return err
+		}
+	}
+
 	var routes []Route
 	if err = tx.Find(&routes, "start_city_id = ? OR end_city_id = ?", c.ID, c.ID).Error; err != nil {
 		return err
@@ -260,10 +650,105 @@ func newAppCitySpaceFromGormCitySpace(space *CitySpace)(*app.CitySpace) {
 // Route Connects two City on a Board
 type Route struct {
 	Model
+	BoardID     ID           `json:"boardId" gorm:"not null;index"`
 	StartCityID ID         `json:"startCityId" gorm:"not null;index"`
 	EndCityID   ID         `json:"endCityId" gorm:"not null;index"`
 	TavernFlag  bool         `json:"tavernFlag" gorm:"not null;default:0"`
+	PlayerRange
 	RouteSpaces []RouteSpace `json:"spaces"`
+
+	StartingTokenOrder int `json:"startingTokenOrder" gorm:"not null;default:0"`
+}
+
+func newGormRouteFromAppRoute(appRoute *app.Route) (*Route, error) {
+	if appRoute == nil {
+		panic("appRoute must not be nil")
+	}
+
+	route := Route{
+		Model: Model{
+			ID:        appRoute.ID,
+			CreatedAt: appRoute.CreatedAt,
+			UpdatedAt: appRoute.UpdatedAt,
+		},
+		BoardID:     appRoute.BoardID,
+		StartCityID: appRoute.StartCityID,
+		EndCityID:   appRoute.EndCityID,
+		TavernFlag:  appRoute.TavernFlag,
+		PlayerRange: PlayerRange{
+			MinPlayers: appRoute.MinPlayers,
+			MaxPlayers: appRoute.MaxPlayers,
+		},
+		RouteSpaces: nil,
+
+		StartingTokenOrder: appRoute.StartingTokenOrder,
+	}
+
+	if appRoute.RouteSpaces != nil {
+		route.RouteSpaces = make([]RouteSpace, 0, len(appRoute.RouteSpaces))
+		for _, space := range appRoute.RouteSpaces {
+			route.RouteSpaces = append(route.RouteSpaces, *newGormRouteSpaceFromAppRouteSpace(&space))
+		}
+	}
+
+	return &route, nil
+}
+
+func newAppRouteFromGormRoute(gormRoute *Route) *app.Route {
+	if gormRoute == nil {
+		panic("gormRoute must not be nil")
+	}
+
+	route := app.Route{
+		Model: app.Model{
+			ID:        gormRoute.ID,
+			CreatedAt: gormRoute.CreatedAt,
+			UpdatedAt: gormRoute.UpdatedAt,
+		},
+		BoardID:     gormRoute.BoardID,
+		StartCityID: gormRoute.StartCityID,
+		EndCityID:   gormRoute.EndCityID,
+		TavernFlag:  gormRoute.TavernFlag,
+		PlayerRange: app.PlayerRange{
+			MinPlayers: gormRoute.MinPlayers,
+			MaxPlayers: gormRoute.MaxPlayers,
+		},
+		RouteSpaces: nil,
+
+		StartingTokenOrder: gormRoute.StartingTokenOrder,
+	}
+
+	if gormRoute.RouteSpaces != nil {
+		route.RouteSpaces = make([]app.RouteSpace, 0, len(gormRoute.RouteSpaces))
+		for _, space := range gormRoute.RouteSpaces {
+			route.RouteSpaces = append(route.RouteSpaces, *newAppRouteSpaceFromGormRouteSpace(&space))
+		}
+	}
+
+	return &route
+}
+
+func (r *Route)BeforeSave(tx *gorm.DB) error {
+	if r.StartCityID == r.EndCityID {
+		return &constraintViolation{
+			msg: fmt.Sprintf("constraint violation: route must connect two different cities (got %d twice)", r.StartCityID),
+		}
+	}
+
+	// Ensure both cities exist on the route's board
+	var result []uint64
+	err := tx.Table("cities").
+		Where("board_id = ? AND id IN ?", r.BoardID, []ID{r.StartCityID, r.EndCityID}).
+		Pluck("id", &result).Error
+	if err != nil {
+		return err
+	}
+	if len(result) != 2 {
+		return &constraintViolation{
+			msg: fmt.Sprintf("constraint violation: cities %d and %d must both belong to board %d", r.StartCityID, r.EndCityID, r.BoardID),
+		}
+	}
+	return nil
 }
 
 func (r *Route)BeforeDelete(tx *gorm.DB) error {
@@ -280,14 +765,45 @@ type RouteSpace struct {
 	Order   int  `json:"order" gorm:"not null;index:uidx_route_space_route_order"`
 }
 
+func newGormRouteSpaceFromAppRouteSpace(space *app.RouteSpace) *RouteSpace {
+	return &RouteSpace{
+		Model: Model{
+			ID:        space.ID,
+			CreatedAt: space.CreatedAt,
+			UpdatedAt: space.UpdatedAt,
+		},
+		RouteID: space.RouteID,
+		Order:   space.Order,
+	}
+}
+
+func newAppRouteSpaceFromGormRouteSpace(space *RouteSpace) *app.RouteSpace {
+	return &app.RouteSpace{
+		Model: app.Model{
+			ID:        space.ID,
+			CreatedAt: space.CreatedAt,
+			UpdatedAt: space.UpdatedAt,
+		},
+		RouteID: space.RouteID,
+		Order:   space.Order,
+	}
+}
+
 // Game represents the game state
 type Game struct {
 	Model
-	Name             string `json:"name" gorm:"not null;index"`
-	Coellen1PlayerID *ID
-	Coellen2PlayerID *ID
-	Coellen3PlayerID *ID
-	Coellen4PlayerID *ID
+	Name               string `json:"name" gorm:"not null;index"`
+	BoardVersionID     *ID    `json:"boardVersionId" gorm:"index"`
+	PrestigeSlotClaims []PrestigeSlotClaim
+}
+
+// Game state
+// A player's claim on a slot of the prestige table of the game's board version
+type PrestigeSlotClaim struct {
+	Model
+	GameID    ID  `gorm:"not null;uniqueIndex:uidx_prestige_slot_claim"`
+	SlotOrder int `gorm:"not null;uniqueIndex:uidx_prestige_slot_claim"`
+	PlayerID  ID  `gorm:"not null;index"`
 }
 
 // Player is part of the game state
@@ -354,3 +870,106 @@ type BonusToken struct {
 	Gold             bool `gorm:"not null"`
 }
 
+
+// PrestigeTable A board's special scoring table. A board has at most one.
+type PrestigeTable struct {
+	Model
+	BoardID ID             `json:"boardId" gorm:"not null;uniqueIndex"`
+	CityID  ID             `json:"cityId" gorm:"not null;index"`
+	Slots   []PrestigeSlot `json:"slots"`
+}
+
+func (t *PrestigeTable)BeforeSave(tx *gorm.DB) error {
+	// Ensure the city exists on the board
+	var result []uint64
+	err := tx.Table("cities").
+		Where("id = ? AND board_id = ?", t.CityID, t.BoardID).
+		Limit(1).
+		Pluck("id", &result).Error
+	if err != nil {
+		return err
+	}
+	if len(result) <= 0 {
+		return &constraintViolation{
+			msg: fmt.Sprintf("constraint violation: city %d must belong to board %d", t.CityID, t.BoardID),
+		}
+	}
+	return nil
+}
+
+func (t *PrestigeTable)BeforeDelete(tx *gorm.DB) error {
+	return tx.Delete(&PrestigeSlot{}, "prestige_table_id = ?", t.ID).Error
+}
+
+func newGormPrestigeTableFromAppPrestigeTable(appTable *app.PrestigeTable) *PrestigeTable {
+	if appTable == nil {
+		panic("appTable must not be nil")
+	}
+
+	table := PrestigeTable{
+		Model: Model{
+			ID:        appTable.ID,
+			CreatedAt: appTable.CreatedAt,
+			UpdatedAt: appTable.UpdatedAt,
+		},
+		BoardID: appTable.BoardID,
+		CityID:  appTable.CityID,
+		Slots:   make([]PrestigeSlot, 0, len(appTable.Slots)),
+	}
+	for _, slot := range appTable.Slots {
+		table.Slots = append(table.Slots, PrestigeSlot{
+			Model: Model{
+				ID:        slot.ID,
+				CreatedAt: slot.CreatedAt,
+				UpdatedAt: slot.UpdatedAt,
+			},
+			PrestigeTableID:   slot.PrestigeTableID,
+			Order:             slot.Order,
+			RequiredPrivilege: slot.RequiredPrivilege,
+			Points:            slot.Points,
+		})
+	}
+
+	return &table
+}
+
+func newAppPrestigeTableFromGormPrestigeTable(gormTable *PrestigeTable) *app.PrestigeTable {
+	if gormTable == nil {
+		panic("gormTable must not be nil")
+	}
+
+	table := app.PrestigeTable{
+		Model: app.Model{
+			ID:        gormTable.ID,
+			CreatedAt: gormTable.CreatedAt,
+			UpdatedAt: gormTable.UpdatedAt,
+		},
+		BoardID: gormTable.BoardID,
+		CityID:  gormTable.CityID,
+		Slots:   make([]app.PrestigeSlot, 0, len(gormTable.Slots)),
+	}
+	for _, slot := range gormTable.Slots {
+		table.Slots = append(table.Slots, app.PrestigeSlot{
+			Model: app.Model{
+				ID:        slot.ID,
+				CreatedAt: slot.CreatedAt,
+				UpdatedAt: slot.UpdatedAt,
+			},
+			PrestigeTableID:   slot.PrestigeTableID,
+			Order:             slot.Order,
+			RequiredPrivilege: slot.RequiredPrivilege,
+			Points:            slot.Points,
+		})
+	}
+
+	return &table
+}
+
+// PrestigeSlot Part of a PrestigeTable
+type PrestigeSlot struct {
+	Model
+	PrestigeTableID   ID  `json:"prestigeTableId" gorm:"not null;index"`
+	Order             int `json:"order" gorm:"not null"`
+	RequiredPrivilege int `json:"requiredPrivilege" gorm:"not null;default:1"`
+	Points            int `json:"points" gorm:"not null;default:0"`
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -30,7 +32,8 @@ func GetIP(r *http.Request) (string, error) {
 func SetCorsHeaders(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PATCH, PUT, DELETE")
-	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding")
+	w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, If-Match")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag")
 }
 
 func SetJSONContentType(w http.ResponseWriter) {
@@ -114,3 +117,24 @@ func TurbolinksVisit(url string, clearCache bool, w http.ResponseWriter, r *http
 	SetJavaScriptContentType(w)
 	fmt.Fprintf(w, `;(function(){%sTurbolinks.visit("%s");})();`, clearCacheStep, url)
 }
+
+// SetETag Tag the response with the version of the record it contains
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf("\"%d\"", version))
+}
+
+// ParseIfMatch Get the record version from an If-Match header set to an ETag from SetETag.
+// Returns zero if the header is missing or "*", meaning any version will do.
+func ParseIfMatch(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match header %q is not a record version", header)
+	}
+	return version, nil
+}